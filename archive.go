@@ -0,0 +1,278 @@
+package easytemplate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiveOpt configures an archive OutputFS.
+type ArchiveOpt func(*archiveOptions)
+
+type archiveOptions struct {
+	fileMode fs.FileMode
+	dirMode  fs.FileMode
+	modTime  time.Time
+}
+
+// WithArchiveFileMode sets the mode recorded for every file in the archive, in place of the mode the file was written with.
+func WithArchiveFileMode(mode fs.FileMode) ArchiveOpt {
+	return func(o *archiveOptions) {
+		o.fileMode = mode.Perm()
+	}
+}
+
+// WithArchiveDirMode sets the mode recorded for every directory in the archive, in place of the mode the directory was created with.
+func WithArchiveDirMode(mode fs.FileMode) ArchiveOpt {
+	return func(o *archiveOptions) {
+		o.dirMode = mode.Perm()
+	}
+}
+
+// WithArchiveModTime sets the modification time recorded for every entry in the archive, in place of the time it was written.
+// Using a fixed time (ie time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)) makes archives of the same output byte for byte reproducible.
+// Zip archives can't record times before 1980, so earlier times are clamped to the start of 1980 in zip entries.
+func WithArchiveModTime(modTime time.Time) ArchiveOpt {
+	return func(o *archiveOptions) {
+		o.modTime = modTime
+	}
+}
+
+// ArchiveFS is an OutputFS streaming files into an archive, which must be closed once rendering is complete to finish the archive.
+type ArchiveFS interface {
+	OutputFS
+	io.Closer
+}
+
+// NewTarGzFS returns an ArchiveFS streaming every file written into a gzip compressed tar archive written to w.
+// Closing it completes the archive, but doesn't close w. Files can't be removed once written.
+func NewTarGzFS(w io.Writer, opts ...ArchiveOpt) ArchiveFS {
+	o := newArchiveOptions(opts)
+
+	gw := gzip.NewWriter(w)
+	if !o.modTime.IsZero() {
+		gw.ModTime = o.modTime
+	}
+	tw := tar.NewWriter(gw)
+
+	a := newTarFS(tw, o)
+	a.close = func() error {
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gw.Close()
+	}
+
+	return a
+}
+
+// minZipTime is the earliest modification time a zip archive can record.
+var minZipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// zipModTime clamps the modification time to the range a zip archive can record.
+func zipModTime(modTime time.Time) time.Time {
+	if modTime.Before(minZipTime) {
+		return minZipTime
+	}
+
+	return modTime
+}
+
+// NewZipFS returns an OutputFS streaming every file written into the zip archive. The caller is responsible for closing the zip.Writer
+// once rendering is complete. Files can't be removed once written.
+func NewZipFS(zw *zip.Writer, opts ...ArchiveOpt) OutputFS {
+	return &archiveFS{
+		opts: newArchiveOptions(opts),
+		writeDir: func(name string, mode fs.FileMode, modTime time.Time) error {
+			h := &zip.FileHeader{Name: name + "/", Modified: zipModTime(modTime)}
+			h.SetMode(fs.ModeDir | mode)
+
+			_, err := zw.CreateHeader(h)
+			return err
+		},
+		writeFile: func(name string, data []byte, mode fs.FileMode, modTime time.Time) error {
+			h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: zipModTime(modTime)}
+			h.SetMode(mode)
+
+			w, err := zw.CreateHeader(h)
+			if err != nil {
+				return err
+			}
+
+			_, err = w.Write(data)
+			return err
+		},
+		entries: map[string]fileInfo{},
+	}
+}
+
+// NewTarFS returns an OutputFS streaming every file written into the tar archive. The caller is responsible for closing the tar.Writer
+// once rendering is complete. Files can't be removed once written.
+func NewTarFS(tw *tar.Writer, opts ...ArchiveOpt) OutputFS {
+	return newTarFS(tw, newArchiveOptions(opts))
+}
+
+func newTarFS(tw *tar.Writer, opts archiveOptions) *archiveFS {
+	return &archiveFS{
+		opts: opts,
+		writeDir: func(name string, mode fs.FileMode, modTime time.Time) error {
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: int64(mode.Perm()), ModTime: modTime})
+		},
+		writeFile: func(name string, data []byte, mode fs.FileMode, modTime time.Time) error {
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: int64(mode.Perm()), Size: int64(len(data)), ModTime: modTime}); err != nil {
+				return err
+			}
+
+			_, err := tw.Write(data)
+			return err
+		},
+		entries: map[string]fileInfo{},
+	}
+}
+
+func newArchiveOptions(opts []ArchiveOpt) archiveOptions {
+	o := archiveOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// archiveFS is an OutputFS writing entries to an archive as they are created.
+type archiveFS struct {
+	mu        sync.Mutex
+	opts      archiveOptions
+	close     func() error
+	writeDir  func(name string, mode fs.FileMode, modTime time.Time) error
+	writeFile func(name string, data []byte, mode fs.FileMode, modTime time.Time) error
+	entries   map[string]fileInfo
+}
+
+func (a *archiveFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name, err := archiveName("write", name)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.entries[name]; ok {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+
+	mode := perm.Perm()
+	if a.opts.fileMode != 0 {
+		mode = a.opts.fileMode
+	}
+	modTime := a.modTime()
+
+	if err := a.writeFile(name, data, mode, modTime); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+
+	a.entries[name] = fileInfo{name: path.Base(name), size: int64(len(data)), mode: mode, modTime: modTime}
+
+	return nil
+}
+
+func (a *archiveFS) MkdirAll(name string, perm fs.FileMode) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	name, err := archiveName("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	// Create parents first, so they precede their contents in the archive
+	dirs := []string{}
+	for dir := name; dir != "."; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+
+	mode := perm.Perm()
+	if a.opts.dirMode != 0 {
+		mode = a.opts.dirMode
+	}
+
+	for _, dir := range dirs {
+		if entry, ok := a.entries[dir]; ok {
+			if !entry.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+			}
+			continue
+		}
+
+		modTime := a.modTime()
+		if err := a.writeDir(dir, mode, modTime); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", dir, err)
+		}
+
+		a.entries[dir] = fileInfo{name: path.Base(dir), mode: fs.ModeDir | mode, modTime: modTime}
+	}
+
+	return nil
+}
+
+// Close completes the archive, if the archive FS owns the archive's writers.
+func (a *archiveFS) Close() error {
+	if a.close == nil {
+		return nil
+	}
+
+	return a.close()
+}
+
+func (a *archiveFS) modTime() time.Time {
+	if !a.opts.modTime.IsZero() {
+		return a.opts.modTime
+	}
+
+	return time.Now()
+}
+
+func (a *archiveFS) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: ErrUnsupported}
+}
+
+func (a *archiveFS) Stat(name string) (fs.FileInfo, error) {
+	name, err := archiveName("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, ok := a.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return entry, nil
+}
+
+// archiveName returns the name of the entry in the archive. Archives can't contain absolute paths, and names escaping the root of
+// the archive (ie ../x) are rejected so the archive can't write outside of the directory it's extracted to.
+func archiveName(op, name string) (string, error) {
+	cleaned := strings.TrimLeft(cleanName(name), "/")
+	if cleaned == "" {
+		cleaned = "."
+	}
+
+	// ValidPath rejects any .. element
+	if !fs.ValidPath(cleaned) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return cleaned, nil
+}
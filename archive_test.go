@@ -0,0 +1,244 @@
+package easytemplate_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveReadFS = fstest.MapFS{
+	"main.js":    {Data: []byte("templateFile(\"tmpl.stmpl\", \"sdk/a.txt\", {name: \"a\"});\ntemplateFile(\"tmpl.stmpl\", \"sdk/models/b.txt\", {name: \"b\"});\n")},
+	"tmpl.stmpl": {Data: []byte("hello {{ .Local.name }}")},
+}
+
+func TestZipFS(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	out := easytemplate.NewZipFS(zw)
+	runArchiveScript(t, out)
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+
+		files[f.Name] = string(data)
+	}
+
+	assert.Equal(t, map[string]string{
+		"sdk/":             "",
+		"sdk/a.txt":        "hello a",
+		"sdk/models/":      "",
+		"sdk/models/b.txt": "hello b",
+	}, files)
+
+	info, err := out.Stat("sdk/models/b.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), info.Size())
+
+	assert.True(t, errors.Is(out.Remove("sdk/a.txt"), easytemplate.ErrUnsupported))
+}
+
+func TestTarFS(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	runArchiveScript(t, easytemplate.NewTarFS(tw))
+	require.NoError(t, tw.Close())
+
+	tr := tar.NewReader(&buf)
+
+	names := []string{}
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		names = append(names, h.Name)
+
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[h.Name] = string(data)
+
+		if h.Typeflag == tar.TypeReg {
+			assert.Equal(t, fs.FileMode(0o644), fs.FileMode(h.Mode))
+		}
+	}
+
+	assert.Equal(t, []string{"sdk/", "sdk/a.txt", "sdk/models/", "sdk/models/b.txt"}, names)
+	assert.Equal(t, "hello b", files["sdk/models/b.txt"])
+}
+
+func TestTarGzFS_Reproducible(t *testing.T) {
+	render := func() []byte {
+		var buf bytes.Buffer
+
+		out := easytemplate.NewTarGzFS(&buf,
+			easytemplate.WithArchiveFileMode(0o600),
+			easytemplate.WithArchiveDirMode(0o700),
+			easytemplate.WithArchiveModTime(time.Unix(0, 0)),
+		)
+		runArchiveScript(t, out)
+		require.NoError(t, out.Close())
+
+		return buf.Bytes()
+	}
+
+	first := render()
+	// Ensure the wall clock moves on between renders
+	time.Sleep(time.Second)
+	second := render()
+
+	assert.Equal(t, first, second)
+
+	gr, err := gzip.NewReader(bytes.NewReader(first))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		assert.True(t, h.ModTime.Equal(time.Unix(0, 0)), h.Name)
+		if h.Typeflag == tar.TypeDir {
+			assert.Equal(t, fs.FileMode(0o700), fs.FileMode(h.Mode), h.Name)
+		} else {
+			assert.Equal(t, fs.FileMode(0o600), fs.FileMode(h.Mode), h.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[h.Name] = string(data)
+	}
+
+	assert.Equal(t, "hello a", files["sdk/a.txt"])
+	assert.Equal(t, "hello b", files["sdk/models/b.txt"])
+}
+
+func TestZipFS_ModTime(t *testing.T) {
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	runArchiveScript(t, easytemplate.NewZipFS(zw, easytemplate.WithArchiveModTime(modTime), easytemplate.WithArchiveFileMode(0o755)))
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	for _, f := range zr.File {
+		assert.True(t, f.Modified.Equal(modTime), f.Name)
+		if !f.Mode().IsDir() {
+			assert.Equal(t, fs.FileMode(0o755), f.Mode().Perm(), f.Name)
+		}
+	}
+}
+
+func TestZipFS_ModTimeBefore1980(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	runArchiveScript(t, easytemplate.NewZipFS(zw, easytemplate.WithArchiveModTime(time.Unix(0, 0))))
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	for _, f := range zr.File {
+		assert.True(t, f.Modified.Equal(time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)), f.Name)
+	}
+}
+
+func TestArchiveFS_RejectsEscapingNames(t *testing.T) {
+	archives := map[string]func(w io.Writer) easytemplate.OutputFS{
+		"zip":    func(w io.Writer) easytemplate.OutputFS { return easytemplate.NewZipFS(zip.NewWriter(w)) },
+		"tar.gz": func(w io.Writer) easytemplate.OutputFS { return easytemplate.NewTarGzFS(w) },
+	}
+
+	tests := []struct {
+		name  string
+		write func(out easytemplate.OutputFS) error
+	}{
+		{
+			name:  "file in parent",
+			write: func(out easytemplate.OutputFS) error { return out.WriteFile("../evil", []byte("evil"), 0o644) },
+		},
+		{
+			name:  "file escaping through a directory",
+			write: func(out easytemplate.OutputFS) error { return out.WriteFile("a/../../evil", []byte("evil"), 0o644) },
+		},
+		{
+			name:  "directory in parent",
+			write: func(out easytemplate.OutputFS) error { return out.MkdirAll("../evil", 0o755) },
+		},
+	}
+	for archive, newFS := range archives {
+		for _, tt := range tests {
+			t.Run(archive+" "+tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				out := newFS(&buf)
+
+				err := tt.write(out)
+				require.ErrorIs(t, err, fs.ErrInvalid)
+
+				var pathErr *fs.PathError
+				assert.ErrorAs(t, err, &pathErr)
+			})
+		}
+	}
+
+	// Absolute paths are written relative to the root of the archive
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	out := easytemplate.NewZipFS(zw)
+	require.NoError(t, out.WriteFile("/sdk/a.txt", []byte("a"), 0o644))
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "sdk/a.txt", zr.File[0].Name)
+}
+
+func runArchiveScript(t *testing.T, out easytemplate.OutputFS) {
+	t.Helper()
+
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(archiveReadFS),
+		easytemplate.WithOutputFS(out),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+}
@@ -0,0 +1,136 @@
+// Package main provides the easytemplate command line tool.
+//
+// Usage:
+//
+//	easytemplate lint [-search dir]... [-front-matter] path...
+//	easytemplate lsp [-search dir]... [-front-matter]
+//
+// lint statically validates the provided templates and scripts (or directories of them) and exits with a non-zero
+// status if any issues are found, making it suitable for use in pre-commit hooks.
+//
+// lsp runs a language server over stdin and stdout, providing diagnostics, completion and go-to-definition for templates and scripts
+// within the editor's workspace.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/speakeasy-api/easytemplate/internal/lsp"
+)
+
+const usage = `Usage: easytemplate <command> [arguments]
+
+Commands:
+  lint    statically validate templates and scripts
+  lsp     run a language server for templates and scripts over stdio
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2 //nolint:mnd
+	}
+
+	switch args[0] {
+	case "lint":
+		return lint(args[1:], stdout, stderr)
+	case "lsp":
+		return serveLSP(args[1:], stdin, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2 //nolint:mnd
+	}
+}
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func lint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var searchLocations stringSlice
+	flags.Var(&searchLocations, "search", "additional location to search for templates and scripts (can be repeated)")
+	frontMatter := flags.Bool("front-matter", false, "parse front-matter blocks at the start of templates")
+
+	if err := flags.Parse(args); err != nil {
+		return 2 //nolint:mnd
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "Usage: easytemplate lint [-search dir]... [-front-matter] path...")
+		return 2 //nolint:mnd
+	}
+
+	opts := []easytemplate.Opt{easytemplate.WithSearchLocations(searchLocations)}
+	if *frontMatter {
+		opts = append(opts, easytemplate.WithFrontMatter())
+	}
+
+	e := easytemplate.New(opts...)
+
+	issues, err := e.Lint(flags.Args()...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue.String())
+	}
+
+	if len(issues) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func serveLSP(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var searchLocations stringSlice
+	flags.Var(&searchLocations, "search", "additional location to search for templates and scripts (can be repeated)")
+	frontMatter := flags.Bool("front-matter", false, "parse front-matter blocks at the start of templates")
+
+	if err := flags.Parse(args); err != nil {
+		return 2 //nolint:mnd
+	}
+
+	server := lsp.NewServer(func(root string) *easytemplate.Engine {
+		opts := []easytemplate.Opt{
+			easytemplate.WithReadFileSystem(os.DirFS(root)),
+			easytemplate.WithSearchLocations(searchLocations),
+		}
+		if *frontMatter {
+			opts = append(opts, easytemplate.WithFrontMatter())
+		}
+
+		return easytemplate.New(opts...)
+	})
+
+	if err := server.Serve(stdin, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+
+	return 0
+}
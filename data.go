@@ -0,0 +1,161 @@
+package easytemplate
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type dataFormat string
+
+const (
+	formatJSON dataFormat = "json"
+	formatYAML dataFormat = "yaml"
+	formatTOML dataFormat = "toml"
+	formatCSV  dataFormat = "csv"
+)
+
+type dataCacheKey struct {
+	path   string
+	format dataFormat
+}
+
+type dataCacheEntry struct {
+	modTime time.Time
+	size    int64
+	value   any
+}
+
+// dataFuncs returns the functions for reading structured data files, available to both templates and js.
+func (e *Engine) dataFuncs() map[string]any {
+	return map[string]any{
+		"readJSON": func(file string) (any, error) { return e.readData(file, formatJSON) },
+		"readYAML": func(file string) (any, error) { return e.readData(file, formatYAML) },
+		"readTOML": func(file string) (any, error) { return e.readData(file, formatTOML) },
+		"readCSV":  func(file string) (any, error) { return e.readData(file, formatCSV) },
+	}
+}
+
+// readData reads and parses the data file, resolving it through the search locations like any other file.
+// Parsed results are cached per path until the file's modification time or size changes, and each read returns its own copy
+// of the cached value so it can be safely modified.
+func (e *Engine) readData(file string, format dataFormat) (any, error) {
+	file = e.resolveLoaderRef(file)
+	resolved := e.resolvePath(file)
+	key := dataCacheKey{path: resolved, format: format}
+
+	// A missing file is reported by readFile below
+	info, statErr := e.statFile(resolved)
+
+	e.dataCacheMu.Lock()
+	defer e.dataCacheMu.Unlock()
+
+	if entry, ok := e.dataCache[key]; ok && statErr == nil && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return copyData(entry.value), nil
+	}
+
+	data, err := e.readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	v, err := parseData(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s as %s: %w", file, format, err)
+	}
+
+	if statErr == nil {
+		if e.dataCache == nil {
+			e.dataCache = map[dataCacheKey]dataCacheEntry{}
+		}
+		e.dataCache[key] = dataCacheEntry{modTime: info.ModTime(), size: info.Size(), value: v}
+	}
+
+	return copyData(v), nil
+}
+
+// copyData returns a deep copy of the maps and slices of a parsed data file, the remaining values are immutable.
+func copyData(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			out[k] = copyData(val)
+		}
+		return out
+	case map[any]any:
+		out := make(map[any]any, len(v))
+		for k, val := range v {
+			out[k] = copyData(val)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = copyData(val)
+		}
+		return out
+	case []map[string]any:
+		out := make([]map[string]any, len(v))
+		for i, val := range v {
+			out[i], _ = copyData(val).(map[string]any)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func parseData(data []byte, format dataFormat) (any, error) {
+	var v any
+
+	switch format {
+	case formatJSON:
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+	case formatTOML:
+		m := map[string]any{}
+		if _, err := toml.Decode(string(data), &m); err != nil {
+			return nil, err
+		}
+		v = m
+	case formatCSV:
+		return parseCSV(data)
+	}
+
+	return v, nil
+}
+
+// parseCSV parses the CSV data, using the first row as the header. Each following row is returned as a map of header to value.
+func parseCSV(data []byte) ([]map[string]any, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []map[string]any{}
+	if len(records) == 0 {
+		return rows, nil
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, col := range header {
+			row[col] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
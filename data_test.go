@@ -0,0 +1,60 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_ReadData(t *testing.T) {
+	written := map[string]string{}
+
+	readFS := fstest.MapFS{
+		"main.js":          {Data: []byte("const cfg = readYAML(\"config.yaml\");\ntemplateFile(\"out.stmpl\", cfg.name + \".txt\", {users: readCSV(\"users.csv\"), version: readTOML(\"data/meta.toml\").version});\n")},
+		"out.stmpl":        {Data: []byte("{{ (readJSON \"settings.json\").level }} {{ .Local.version }}{{ range .Local.users }} {{ .name }}={{ .age }}{{ end }}")},
+		"data/config.yaml": {Data: []byte("name: report\n")},
+		"data/users.csv":   {Data: []byte("name,age\nalice,30\nbob,25\n")},
+		"data/meta.toml":   {Data: []byte("version = \"1.0\"\n")},
+		"settings.json":    {Data: []byte("{\"level\": 3}")},
+	}
+
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"data"}),
+		easytemplate.WithReadFileSystem(readFS),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"report.txt": "3 1.0 alice=30 bob=25"}, written)
+
+	// Parsed data is cached per path
+	readFS["settings.json"] = &fstest.MapFile{Data: []byte("{\"level\": 4}")}
+
+	out, err := e.TemplateStringInput(context.Background(), "test", "{{ (readJSON \"settings.json\").level }}", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3", out)
+
+	// Each read gets its own copy of the cached data
+	out, err = e.TemplateStringInput(context.Background(), "test", "```sjs\nreadJSON(\"settings.json\").level = 5;\nrender(readJSON(\"settings.json\").level);\nsjs```", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "3", out)
+
+	// Until the file changes
+	readFS["settings.json"] = &fstest.MapFile{Data: []byte("{\"level\": 4}"), ModTime: time.Now()}
+
+	out, err = e.TemplateStringInput(context.Background(), "test", "{{ (readJSON \"settings.json\").level }}", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "4", out)
+}
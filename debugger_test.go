@@ -0,0 +1,100 @@
+package easytemplate_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateString_DebuggerBreakpointsInSJSBlocks(t *testing.T) {
+	readFS := fstest.MapFS{
+		"scripts/main.js": {Data: []byte("function shout(s) {\n  var upper = s.toUpperCase();\n  return upper;\n}\nregisterTemplateFunc(\"shout\", shout);\n")},
+		"templates/page.stmpl": {Data: []byte(`{{ .Local.title }}
+` + "```sjs" + `
+var greeting = "hello";
+render(templateStringInput("inline", "{{ shout \"" + greeting + "\" }}", {}));
+` + "sjs```" + `
+done
+`)},
+		"templates/error.stmpl": {Data: []byte("first line\n\n```sjs\nvar a = 1;\nthrow new Error(\"failed\");\nsjs```\n")},
+	}
+
+	e := easytemplate.New(easytemplate.WithReadFileSystem(readFS))
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	type pause struct {
+		event goja.DebugEvent
+		pos   string
+		stack []string
+	}
+	pauses := []pause{}
+
+	hook := easytemplate.SkipInternalScripts(func(ctx *goja.DebugContext, event goja.DebugEvent, pos goja.DebugPosition) goja.DebugAction {
+		stack := []string{}
+		for _, frame := range ctx.CallStack() {
+			if p := frame.Position(); p.Filename != "" {
+				stack = append(stack, fmt.Sprintf("%s:%d", p.Filename, p.Line))
+			}
+		}
+		pauses = append(pauses, pause{event: event, pos: fmt.Sprintf("%s:%d", pos.Filename, pos.Line), stack: stack})
+
+		// Step in from the breakpoint on the line calling the template function
+		if event == goja.DebugEventBreakpoint && pos.Line == 4 {
+			return goja.DebugStepIn
+		}
+
+		return goja.DebugContinue
+	})
+
+	// Count the pauses within the engine's own scripts (ie the script creating LocalComputed), which the hook steps through
+	internalPauses := 0
+	dbg := goja.NewDebugger(func(ctx *goja.DebugContext, event goja.DebugEvent, pos goja.DebugPosition) goja.DebugAction {
+		if strings.HasPrefix(pos.Filename, easytemplate.InternalScriptPrefix) {
+			internalPauses++
+		}
+
+		return hook(ctx, event, pos)
+	})
+	e.Runtime().SetDebugger(dbg)
+
+	dbg.SetBreakpoint("templates/page.stmpl", 3, 0)
+	dbg.SetBreakpoint("templates/page.stmpl", 4, 0)
+
+	err = e.RunScript(context.Background(), "scripts/main.js")
+	require.NoError(t, err)
+
+	out, err := e.TemplateString(context.Background(), "templates/page.stmpl", map[string]any{"title": "Page"})
+	require.NoError(t, err)
+	assert.Equal(t, "Page\nHELLO\ndone\n", out)
+
+	require.GreaterOrEqual(t, len(pauses), 3)
+	assert.Positive(t, internalPauses)
+	for _, p := range pauses {
+		assert.False(t, strings.HasPrefix(p.pos, easytemplate.InternalScriptPrefix), "paused within internal script %s", p.pos)
+	}
+
+	// Breakpoints are hit on the lines of the template the sjs block is on
+	assert.Equal(t, goja.DebugEventBreakpoint, pauses[0].event)
+	assert.Equal(t, "templates/page.stmpl:3", pauses[0].pos)
+	assert.Equal(t, goja.DebugEventBreakpoint, pauses[1].event)
+	assert.Equal(t, "templates/page.stmpl:4", pauses[1].pos)
+
+	// Stepping in goes from the sjs block through the template function into the registered JS function
+	assert.Equal(t, goja.DebugEventStep, pauses[2].event)
+	assert.Equal(t, "scripts/main.js:2", pauses[2].pos)
+	assert.Equal(t, []string{"scripts/main.js:2", "templates/page.stmpl:4"}, pauses[2].stack)
+
+	// Errors within sjs blocks are reported at the line within the template
+	_, err = e.TemplateString(context.Background(), "templates/error.stmpl", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "templates/error.stmpl:5:")
+}
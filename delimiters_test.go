@@ -0,0 +1,49 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateString_Delimiters(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantOut  string
+	}{
+		{
+			name:     "engine delimiters",
+			template: "engine.stmpl",
+			wantOut:  "{{ .Local.name }} test\n```go\ncode\n```\ntest",
+		},
+		{
+			name:     "pragma delimiters",
+			template: "pragma.stmpl",
+			wantOut:  "[[ .Local.name ]] test\nrendered",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(
+				easytemplate.WithDelimiters("[[", "]]"),
+				easytemplate.WithScriptDelimiters("<%", "%>"),
+				easytemplate.WithReadFileSystem(fstest.MapFS{
+					"engine.stmpl": {Data: []byte("[[- recurse 1 -]]\n{{ .Local.name }} [[ .Local.name ]]\n```go\ncode\n```\n<%\nrender(\"[[ .Local.name ]]\");\n%>")},
+					"pragma.stmpl": {Data: []byte("#easytemplate delims=<<,>> script=@@js,js@@\n[[ .Local.name ]] << .Local.name >>\n@@js\nrender(\"rendered\");\njs@@")},
+				}),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			out, err := e.TemplateString(context.Background(), tt.template, map[string]any{"name": "test"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -8,13 +8,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/dop251/goja"
 	"github.com/dop251/goja/debugger"
+	"github.com/speakeasy-api/easytemplate/internal/funcs"
+	"github.com/speakeasy-api/easytemplate/internal/schema"
 	"github.com/speakeasy-api/easytemplate/internal/template"
 	"github.com/speakeasy-api/easytemplate/internal/utils"
 	"github.com/speakeasy-api/easytemplate/internal/vm"
@@ -31,12 +38,25 @@ var (
 	ErrNotInitialized = errors.New("engine has not been initialized")
 	// ErrReserved is returned when a template or js function is reserved and can't be overridden.
 	ErrReserved = errors.New("function is a reserved function and can't be overridden")
+	// ErrTemplateNotFound is returned when a template, script or other file can't be found, see NotFoundError.
+	ErrTemplateNotFound = errors.New("template not found")
 	// ErrInvalidArg is returned when an invalid argument is passed to a function.
 	ErrInvalidArg = errors.New("invalid argument")
 	// ErrTemplateCompilation is returned when a template fails to compile.
 	ErrTemplateCompilation = errors.New("template compilation failed")
+	// ErrUnsupported is returned by an OutputFS that doesn't support an operation, ie removing a file already written to an archive.
+	ErrUnsupported = errors.New("operation not supported")
 	// ErrNativePanic is returned when a Go native function panics with a non-goja error.
 	ErrNativePanic = errors.New("native function panic")
+	// ErrUndefinedValue is returned in strict mode when render() is called with undefined or a template function registered from js returns undefined.
+	ErrUndefinedValue = template.ErrUndefinedValue
+	// ErrNoOutputFile is returned when front-matter is enabled and a template is templated to a file without an output file and its
+	// front-matter doesn't declare one.
+	ErrNoOutputFile = template.ErrNoOutputFile
+	// ErrSchemaValidation is returned when data doesn't match the schema declared for it.
+	ErrSchemaValidation = schema.ErrValidation
+	// ErrUnsupportedSchemaKeyword is returned when a schema uses a JSON Schema keyword that isn't supported, see WithSchemaValidation.
+	ErrUnsupportedSchemaKeyword = schema.ErrUnsupportedKeyword
 )
 
 // CallContext is the context that is passed to go functions when called from js.
@@ -46,6 +66,24 @@ type CallContext struct {
 	Ctx context.Context //nolint:containedctx // runtime context is necessarily stored in a struct as it jumps from Go to JS.
 }
 
+// WriteAborter is implemented by writers returned from the function set with WithStreamWriteFunc that can discard a partially
+// written file, CloseWithError is called in place of Close when the file fails to render.
+type WriteAborter = template.WriteAborter
+
+// OutputMode determines how a template's output is escaped, see WithOutputMode.
+type OutputMode = template.OutputMode
+
+// InternalScriptPrefix prefixes the file names of the scripts the engine runs itself (ie to create each template's LocalComputed context),
+// so debuggers can tell them apart from templates and scripts, see SkipInternalScripts.
+const InternalScriptPrefix = vm.InternalScriptPrefix
+
+const (
+	// OutputText renders templates with text/template, without any escaping. This is the default.
+	OutputText = template.OutputText
+	// OutputHTML renders templates with html/template, contextually escaping output for HTML.
+	OutputHTML = template.OutputHTML
+)
+
 // Opt is a function that configures the Engine.
 type Opt func(*Engine)
 
@@ -57,8 +95,13 @@ func WithSearchLocations(searchLocations []string) Opt {
 }
 
 // WithReadFileSystem sets the file system to use for reading files. This is useful for embedded files or reading from locations other than disk.
+// To read from multiple file systems use WithLayers instead, the two can't be combined.
 func WithReadFileSystem(fs fs.FS) Opt {
 	return func(e *Engine) {
+		if len(e.layers) > 0 {
+			panic(fmt.Errorf("%w: WithReadFileSystem can't be combined with WithLayers", ErrInvalidArg))
+		}
+
 		e.readFS = fs
 	}
 }
@@ -67,27 +110,116 @@ func WithReadFileSystem(fs fs.FS) Opt {
 func WithWriteFunc(writeFunc func(string, []byte) error) Opt {
 	return func(e *Engine) {
 		e.templator.WriteFunc = writeFunc
+		e.templator.StreamWriteFunc = nil
+		e.writesToDisk = false
+	}
+}
+
+// WithStreamWriteFunc sets a function opening a writer for each file written, in place of the write function.
+// Non-recursive templates are executed directly into the writer rather than being held in memory, unless their output is
+// normalized (see WithNormalizer) or formatted (see WithFormatter). The writer is closed once the file has been written.
+// As output is written as it's rendered, a file that fails to render will have been partially written. If the writer implements
+// WriteAborter (as *io.PipeWriter does) CloseWithError is called in place of Close, so the partial file can be discarded.
+//
+// Example:
+//
+//	e := easytemplate.New(easytemplate.WithStreamWriteFunc(func(name string) (io.WriteCloser, error) {
+//		return os.Create(name)
+//	}))
+func WithStreamWriteFunc(streamWriteFunc func(string) (io.WriteCloser, error)) Opt {
+	return func(e *Engine) {
+		e.templator.StreamWriteFunc = streamWriteFunc
+		e.writesToDisk = false
 	}
 }
 
 // WithTemplateFuncs allows for providing additional template functions to the engine, available to all templates.
+// The engine's built-in helper functions (ie escapeJSON) may be overridden, the engine's own functions (ie templateFile) can't be.
 func WithTemplateFuncs(funcs map[string]any) Opt {
 	return func(e *Engine) {
 		for k, v := range funcs {
-			if _, ok := e.templator.TmplFuncs[k]; ok {
+			if e.isReservedTemplateFunc(k) {
 				panic(fmt.Errorf("%s is reserved: %w", k, ErrReserved))
 			}
 
+			delete(e.builtinTmplFuncs, k)
 			e.templator.TmplFuncs[k] = v
 		}
 	}
 }
 
+// WithStdlib enables the standard library of helper functions, registered both as template functions and js globals
+// with identical behaviour:
+//
+//   - camelCase, pascalCase, snakeCase, kebabCase - convert between cases, ie {{ snakeCase "userID" }} -> user_id.
+//   - pluralize, singularize - convert English words between their singular and plural forms.
+//   - sanitizeIdentifier - replaces any characters not valid in an identifier with underscores.
+//   - goIdentifier, tsIdentifier, pyIdentifier - sanitize the identifier and escape it if it is a keyword of the language, ie type -> type_.
+//
+// Like the other built-in helpers they can be overridden with WithTemplateFuncs or WithJSFuncs.
+func WithStdlib() Opt {
+	return func(e *Engine) {
+		e.registerBuiltinFuncs(funcs.Stdlib())
+	}
+}
+
+// WithWhitespaceFuncs enables the template functions for managing whitespace and indentation when generating code:
+// indent, nindent, dedent, trimBlankLines, wrap, comment and join.
+//
+// Like the other built-in helpers they can be overridden with WithTemplateFuncs.
+func WithWhitespaceFuncs() Opt {
+	return func(e *Engine) {
+		e.registerBuiltinTmplFuncs(funcs.Whitespace())
+	}
+}
+
+// Normalizer configures the whitespace normalisation applied to the output of templateFile, see WithNormalizer.
+type Normalizer = template.Normalizer
+
+// WithNormalizer enables normalising the whitespace of the output of templateFile (and TemplateDir) before it is formatted and written,
+// for example collapsing the blank lines left behind by template actions.
+//
+// Example:
+//
+//	e := easytemplate.New(easytemplate.WithNormalizer(easytemplate.Normalizer{
+//		CollapseBlankLines:     true,
+//		TrimTrailingWhitespace: true,
+//		FinalNewline:           true,
+//	}))
+func WithNormalizer(n Normalizer) Opt {
+	return func(e *Engine) {
+		e.templator.Normalizer = n
+	}
+}
+
+// WithOutputMode sets the default output mode for templates. OutputHTML renders templates with html/template,
+// contextually escaping their output, while OutputText (the default) renders them with text/template.
+// A template may override the mode with a first line pragma, ie #easytemplate mode=html.
+func WithOutputMode(mode OutputMode) Opt {
+	return func(e *Engine) {
+		e.templator.OutputMode = mode
+	}
+}
+
+// WithOutputModeForExt sets the output mode for templates written to files with the provided extensions (ie ".html", ".htm").
+// This takes precedence over the default output mode, but not over a template's pragma.
+func WithOutputModeForExt(mode OutputMode, exts ...string) Opt {
+	return func(e *Engine) {
+		if e.templator.OutputModesByExt == nil {
+			e.templator.OutputModesByExt = map[string]OutputMode{}
+		}
+
+		for _, ext := range exts {
+			e.templator.OutputModesByExt[strings.ToLower(ext)] = mode
+		}
+	}
+}
+
 // WithJSFuncs allows for providing additional functions available to javascript in the engine.
 func WithJSFuncs(funcs map[string]func(call CallContext) goja.Value) Opt {
 	return func(e *Engine) {
 		for k, v := range funcs {
-			if _, ok := e.jsFuncs[k]; ok {
+			if e.isReservedJSName(k) {
 				panic(fmt.Errorf("%s is reserved: %w", k, ErrReserved))
 			}
 
@@ -103,6 +235,16 @@ func WithJSFiles(files map[string]string) Opt {
 	}
 }
 
+// WithFileAccess exposes the engine's files to js as the methods of a global fs object: fs.readFile, fs.exists, fs.glob and fs.readDir,
+// and to templates as the readFile, fileExists and glob template functions. As with all other reads by the engine files are read from the
+// read file system if set, or disk otherwise, and relative paths are resolved through the search locations.
+func WithFileAccess() Opt {
+	return func(e *Engine) {
+		e.jsGlobals["fs"] = e.fileFuncs()
+		e.registerBuiltinTmplFuncs(e.fileTmplFuncs())
+	}
+}
+
 // WithTracer attaches an OpenTelemetry tracer to the engine and enables tracing support.
 func WithTracer(t trace.Tracer) Opt {
 	return func(e *Engine) {
@@ -124,6 +266,85 @@ func WithRandSource(randSource func() float64) Opt {
 	}
 }
 
+// WithErrorMode sets how the engine handles failing templateFile calls, see ErrorMode. Defaults to FailFast.
+func WithErrorMode(mode ErrorMode) Opt {
+	return func(e *Engine) {
+		e.errorMode = mode
+	}
+}
+
+// WithStrictMode enables strict mode for the engine. In strict mode templates fail to render when they reference a missing map key
+// (text/template's missingkey=error option), when render() is called with undefined in an sjs block, or when a template function
+// registered with registerTemplateFunc returns undefined.
+func WithStrictMode() Opt {
+	return func(e *Engine) {
+		e.templator.Strict = true
+	}
+}
+
+// WithPartials registers directories of partial templates, found through the engine's search locations and read file system.
+// Every .stmpl file in the directories is available to all templates via {{ template "name" . }}, where name is the file's
+// path relative to the directory without the .stmpl extension (ie partials/components/header.stmpl -> "components/header").
+// Any {{ define }} blocks within the partials are also available to all templates.
+//
+// Templates can also declare {{ extends "layout.stmpl" }} to render within a layout, overriding any {{ block }}s it declares with {{ define }}.
+// Like partials, layouts are plain templates and can't contain sjs blocks or front-matter.
+func WithPartials(dirs ...string) Opt {
+	return func(e *Engine) {
+		e.partialDirs = append(e.partialDirs, dirs...)
+	}
+}
+
+// WithDelimiters sets the action delimiters used by templates, defaulting to {{ and }}.
+// This is useful when generating output that itself uses {{ }}, for example other templating languages.
+func WithDelimiters(left, right string) Opt {
+	return func(e *Engine) {
+		e.templator.Delims = [2]string{left, right}
+	}
+}
+
+// WithScriptDelimiters sets the markers used to open and close inline scripts in templates, defaulting to ```sjs and sjs```.
+// This is useful when generating Markdown containing fenced code blocks.
+//
+// Both the action and script delimiters can also be overridden for a single template with a pragma on its first line, ie:
+//
+//	#easytemplate delims=[[,]] script=<%,%>
+func WithScriptDelimiters(opening, closing string) Opt {
+	return func(e *Engine) {
+		e.templator.ScriptDelims = [2]string{opening, closing}
+	}
+}
+
+// WithFrontMatter enables parsing of front-matter blocks at the start of templates. A template may start with a YAML block
+// delimited by --- lines or a TOML block delimited by +++ lines, declaring metadata about the template (see the README for the supported keys).
+// The block is removed before templating and made available as .Meta in the template and context.Meta in js.
+func WithFrontMatter() Opt {
+	return func(e *Engine) {
+		e.templator.FrontMatter = true
+	}
+}
+
+// WithSchemaValidation enables validation of the data passed to template files. When a template has a companion
+// schema file alongside it (ie templates/client.stmpl -> templates/client.schema.json) the data available as .Local
+// is validated against it before the template is rendered.
+//
+// Schemas support a subset of JSON Schema: the keywords type, properties, required, additionalProperties, items and enum, plus the
+// annotations $schema, $id, $comment, title, description, default and examples. A schema using any other keyword (ie $ref, pattern
+// or oneOf) fails with ErrUnsupportedSchemaKeyword rather than being partially applied.
+func WithSchemaValidation() Opt {
+	return func(e *Engine) {
+		e.templator.ValidateSchemas = true
+	}
+}
+
+// WithGlobalSchema sets a JSON Schema that the global data provided to Init is validated against, supporting the same subset of
+// JSON Schema as WithSchemaValidation.
+func WithGlobalSchema(schema []byte) Opt {
+	return func(e *Engine) {
+		e.globalSchema = schema
+	}
+}
+
 // WithDebugger enables DAP (Debug Adapter Protocol) debugging on the specified
 // TCP port. When set, Init() will start a debug server and block until a DAP
 // client (e.g., VS Code) connects. After Init returns, all RunScript,
@@ -141,6 +362,22 @@ func WithDebugger(port int) Opt {
 	}
 }
 
+// SkipInternalScripts wraps a goja debug hook so stepping never pauses within the scripts run by the engine itself (see InternalScriptPrefix),
+// stepping on through them to the next template or script instead.
+//
+// Example:
+//
+//	e.Runtime().SetDebugger(goja.NewDebugger(easytemplate.SkipInternalScripts(hook)))
+func SkipInternalScripts(hook goja.DebugHookFunc) goja.DebugHookFunc {
+	return func(ctx *goja.DebugContext, event goja.DebugEvent, pos goja.DebugPosition) goja.DebugAction {
+		if event == goja.DebugEventStep && strings.HasPrefix(pos.Filename, InternalScriptPrefix) {
+			return goja.DebugStepIn
+		}
+
+		return hook(ctx, event, pos)
+	}
+}
+
 // Engine provides the templating engine.
 type Engine struct {
 	searchLocations []string
@@ -158,37 +395,89 @@ type Engine struct {
 	debugPort    int
 	debugSession *debugger.AttachSession
 
+	errorMode       ErrorMode
+	collectedErrors []*TemplateError
+
+	globalSchema []byte
+
+	partialDirs []string
+
+	pathAliases map[string]string
+
+	layers     []fs.FS
+	layerStack []layerFrame
+	// parentRead is the layer the next read of a template is taken from while templateParent renders it
+	parentRead *layerFrame
+
+	// jsGlobals are Go values set as globals in the vm before any other js functions or files
+	jsGlobals map[string]any
+
+	dataCache   map[dataCacheKey]dataCacheEntry
+	dataCacheMu sync.Mutex
+
+	// builtinTmplFuncs are the helper template functions provided by the engine that may be overridden by the user
+	builtinTmplFuncs map[string]struct{}
+
+	// writesToDisk is true while the default write function is in use, so TemplateDir creates the output directories on disk
+	writesToDisk bool
+
 	vm *vm.VM
 }
 
+// reservedTemplateFuncs are the template functions provided by the engine itself, which can't be overridden.
+var reservedTemplateFuncs = []string{"templateFile", "templateString", "templateStringInput", "templateParent", "recurse", "extends"}
+
+// IsReserved returns true if the name is reserved for one of the engine's own template functions (ie templateFile),
+// which can't be provided with WithTemplateFuncs.
+func IsReserved(name string) bool {
+	for _, reserved := range reservedTemplateFuncs {
+		if name == reserved {
+			return true
+		}
+	}
+
+	return false
+}
+
 // New creates a new Engine with the provided options.
 func New(opts ...Opt) *Engine {
 	t := &template.Templator{
-		// Reserving the names for now
-		TmplFuncs: map[string]any{
-			"templateFile":        nil,
-			"templateString":      nil,
-			"templateStringInput": nil,
-		},
+		TmplFuncs: map[string]any{},
 		WriteFunc: func(s string, b []byte) error {
 			return os.WriteFile(s, b, os.ModePerm)
 		},
 	}
 
+	// Reserving the names for now
+	for _, name := range reservedTemplateFuncs {
+		t.TmplFuncs[name] = nil
+	}
+	// extends is handled before the template is executed, see WithPartials
+	t.TmplFuncs["extends"] = func(string) string { return "" }
+
 	e := &Engine{
-		templator: t,
-		jsFuncs:   map[string]func(call CallContext) goja.Value{},
-		jsFiles:   map[string]string{},
+		templator:        t,
+		writesToDisk:     true,
+		jsFuncs:          map[string]func(call CallContext) goja.Value{},
+		jsFiles:          map[string]string{},
+		jsGlobals:        map[string]any{},
+		builtinTmplFuncs: map[string]struct{}{},
 	}
 
+	e.registerBuiltinTmplFuncs(funcs.Escape())
+	e.registerBuiltinFuncs(e.dataFuncs())
+
 	t.ReadFunc = e.readFile
+	t.ResolveRef = e.resolveRef
 
 	e.jsFuncs = map[string]func(call CallContext) goja.Value{
 		"require":                e.require,
 		"recurse":                e.recurseJS,
 		"templateFile":           e.templateFileJS,
+		"templateDir":            e.templateDirJS,
 		"templateString":         e.templateStringJS,
 		"templateStringInput":    e.templateStringInputJS,
+		"templateParent":         e.templateParentJS,
 		"registerTemplateFunc":   e.registerTemplateFunc,
 		"unregisterTemplateFunc": e.unregisterTemplateFunc,
 	}
@@ -197,6 +486,10 @@ func New(opts ...Opt) *Engine {
 		opt(e)
 	}
 
+	if len(e.layers) > 0 {
+		e.readFS = overlayFS{layers: e.layers}
+	}
+
 	if e.tracer == nil {
 		e.tracer = noop.NewTracerProvider().Tracer("easytemplate")
 	}
@@ -250,11 +543,11 @@ func (e *Engine) RunScript(ctx context.Context, scriptFile string) error {
 		return fmt.Errorf("failed to read script file: %w", err)
 	}
 
-	if _, err := e.vm.Run(ctx, scriptFile, string(script)); err != nil {
-		return err
-	}
+	flush := e.collectErrors()
 
-	return nil
+	_, err = e.vm.Run(ctx, scriptFile, string(script))
+
+	return flush(err)
 }
 
 // RunFunction will run the named function if it already exists within the environment, for example if it was defined in a script run by RunScript.
@@ -264,16 +557,26 @@ func (e *Engine) RunFunction(ctx context.Context, fnName string, args ...any) (g
 		return nil, ErrNotInitialized
 	}
 
-	return e.vm.RunFunction(ctx, fnName, args...)
+	flush := e.collectErrors()
+
+	res, err := e.vm.RunFunction(ctx, fnName, args...)
+	if err = flush(err); err != nil {
+		return nil, err
+	}
+
+	return res, nil
 }
 
 // TemplateFile runs the provided template file, with the provided data and writes the result to the provided outFile.
+// If outFile is empty, the output file declared in the template's front-matter is used.
 func (e *Engine) TemplateFile(ctx context.Context, templateFile string, outFile string, data any) error {
 	if e.vm == nil {
 		return ErrNotInitialized
 	}
 
-	return e.templator.TemplateFile(ctx, e.vm, templateFile, outFile, data)
+	flush := e.collectErrors()
+
+	return flush(e.templator.TemplateFile(ctx, e.vm, templateFile, outFile, data))
 }
 
 // TemplateString runs the provided template file, with the provided data and returns the rendered result.
@@ -282,7 +585,27 @@ func (e *Engine) TemplateString(ctx context.Context, templateFilePath string, da
 		return "", ErrNotInitialized
 	}
 
-	return e.templator.TemplateString(ctx, e.vm, templateFilePath, data)
+	flush := e.collectErrors()
+
+	out, err := e.templator.TemplateString(ctx, e.vm, templateFilePath, data)
+	if err = flush(err); err != nil {
+		return "", err
+	}
+
+	return out, nil
+}
+
+// TemplateTo runs the provided template file, with the provided data and writes the rendered result to w.
+// Non-recursive templates are executed directly into w after their sjs blocks are evaluated, rather than being held in memory,
+// so if an error is returned w may have been partially written to.
+func (e *Engine) TemplateTo(ctx context.Context, w io.Writer, templateFilePath string, data any) error {
+	if e.vm == nil {
+		return ErrNotInitialized
+	}
+
+	flush := e.collectErrors()
+
+	return flush(e.templator.TemplateTo(ctx, e.vm, w, templateFilePath, data))
 }
 
 // TemplateStringInput runs the provided template string, with the provided data and returns the rendered result.
@@ -291,7 +614,14 @@ func (e *Engine) TemplateStringInput(ctx context.Context, name, template string,
 		return "", ErrNotInitialized
 	}
 
-	return e.templator.TemplateStringInput(ctx, e.vm, name, template, data)
+	flush := e.collectErrors()
+
+	out, err := e.templator.TemplateStringInput(ctx, e.vm, name, template, data)
+	if err = flush(err); err != nil {
+		return "", err
+	}
+
+	return out, nil
 }
 
 // Runtime returns the underlying goja Runtime, or nil if the engine has not been initialized.
@@ -320,11 +650,28 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 		return nil, ErrAlreadyInitialized
 	}
 
+	if e.globalSchema != nil {
+		s, err := schema.Parse(e.globalSchema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid global schema: %w", err)
+		}
+
+		if err := s.Validate(".Global", data); err != nil {
+			return nil, fmt.Errorf("invalid global data: %w", err)
+		}
+	}
+
 	v, err := vm.New(e.randSource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vm: %w", err)
 	}
 
+	for name, global := range e.jsGlobals {
+		if err := v.Set(name, global); err != nil {
+			return nil, fmt.Errorf("failed to set js global %s: %w", name, err)
+		}
+	}
+
 	for name, content := range e.jsFiles {
 		_, err := v.RunString(content)
 		if err != nil {
@@ -352,6 +699,8 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 	// This need to have the vm passed in so that the functions can be called
 	e.templator.TmplFuncs["templateFile"] = func(v *vm.VM) func(string, string, any) (string, error) {
 		return func(templateFile, outFile string, data any) (string, error) {
+			templateFile = e.resolveRef(templateFile, e.templator.CurrentTemplate())
+
 			var err error
 			_, span := e.tracer.Start(ctx, "templateFile", trace.WithAttributes(
 				attribute.String("templateFile", templateFile),
@@ -367,6 +716,11 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 
 			err = e.templator.TemplateFile(ctx, v, templateFile, outFile, data)
 			if err != nil {
+				if e.errorMode == CollectErrors {
+					e.collectError(templateFile, outFile, "", err)
+					return "", nil
+				}
+
 				return "", err
 			}
 
@@ -375,6 +729,8 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 	}(v)
 	e.templator.TmplFuncs["templateString"] = func(v *vm.VM) func(string, any) (string, error) {
 		return func(templateFile string, data any) (string, error) {
+			templateFile = e.resolveRef(templateFile, e.templator.CurrentTemplate())
+
 			templated, err := e.templator.TemplateString(ctx, v, templateFile, data)
 			if err != nil {
 				return "", err
@@ -393,6 +749,11 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 			return templated, nil
 		}
 	}(v)
+	e.templator.TmplFuncs["templateParent"] = func(v *vm.VM) func(any) (string, error) {
+		return func(data any) (string, error) {
+			return e.templateParent(ctx, v, data)
+		}
+	}(v)
 	e.templator.TmplFuncs["recurse"] = func(v *vm.VM) func(int) (string, error) {
 		return func(numTimes int) (string, error) {
 			templated, err := e.templator.Recurse(v, numTimes)
@@ -404,19 +765,23 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 		}
 	}(v)
 
-	if _, err := v.Run(ctx, "initCreateComputedContextObject", `function createComputedContextObject() { return {}; }`); err != nil {
+	if _, err := v.Run(ctx, vm.InternalScriptPrefix+"initCreateComputedContextObject", `function createComputedContextObject() { return {}; }`); err != nil {
 		return nil, utils.HandleJSError("failed to init createComputedContextObject", err)
 	}
 
-	globalComputed, err := v.Run(ctx, "globalCreateComputedContextObject", `createComputedContextObject();`)
+	globalComputed, err := v.Run(ctx, vm.InternalScriptPrefix+"globalCreateComputedContextObject", `createComputedContextObject();`)
 	if err != nil {
 		return nil, utils.HandleJSError("failed to init globalComputed", err)
 	}
 
+	if err := e.loadPartials(); err != nil {
+		return nil, err
+	}
+
 	e.templator.SetContextData(data, globalComputed)
 	e.templator.RebuildBaseTemplate()
 
-	if err := v.Set("context", &template.Context{
+	if err := v.Set(template.ContextGlobal, &template.Context{
 		Global:         data,
 		GlobalComputed: globalComputed,
 		Local:          data,
@@ -428,6 +793,72 @@ func (e *Engine) init(ctx context.Context, data any) (*vm.VM, error) {
 	return v, nil
 }
 
+func (e *Engine) registerBuiltinTmplFuncs(fns map[string]any) {
+	for name, fn := range fns {
+		e.templator.TmplFuncs[name] = fn
+		e.builtinTmplFuncs[name] = struct{}{}
+	}
+}
+
+// templateFuncNames returns the sorted names of the functions available to templates, including those provided by the engine
+// (ie templateFile) but not those predefined by text/template.
+func (e *Engine) templateFuncNames() []string {
+	names := make([]string, 0, len(e.templator.TmplFuncs))
+	for name := range e.templator.TmplFuncs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// jsGlobalNames returns the sorted names of the globals the engine makes available to javascript, including its functions
+// (ie templateFile), those provided by WithJSFuncs or options such as WithStdlib and the context object. render is included, though only
+// available within sjs blocks.
+func (e *Engine) jsGlobalNames() []string {
+	names := []string{template.ContextGlobal, template.RenderGlobal}
+
+	for name := range e.jsFuncs {
+		names = append(names, name)
+	}
+	for name := range e.jsGlobals {
+		if _, ok := e.jsFuncs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// registerBuiltinFuncs registers the functions as overridable template functions and as js globals.
+func (e *Engine) registerBuiltinFuncs(fns map[string]any) {
+	e.registerBuiltinTmplFuncs(fns)
+
+	for name, fn := range fns {
+		e.jsGlobals[name] = fn
+	}
+}
+
+// isReservedJSName returns true if the name is used by one of the engine's js functions.
+func (e *Engine) isReservedJSName(name string) bool {
+	_, ok := e.jsFuncs[name]
+	return ok
+}
+
+// isReservedTemplateFunc returns true if the template function already exists and isn't a built-in helper that can be overridden.
+func (e *Engine) isReservedTemplateFunc(name string) bool {
+	if _, ok := e.templator.TmplFuncs[name]; !ok {
+		return false
+	}
+
+	_, builtin := e.builtinTmplFuncs[name]
+
+	return !builtin
+}
+
 func (e *Engine) unregisterTemplateFunc(call CallContext) goja.Value {
 	name := call.Argument(0).String()
 	if _, ok := e.templator.TmplFuncs[name]; !ok {
@@ -445,12 +876,16 @@ func (e *Engine) require(call CallContext) goja.Value {
 
 	scriptPath := call.Argument(0).String()
 
-	script, err := e.readFile(scriptPath)
-	if err != nil {
-		currentCallStack := vm.CaptureCallStack(0, nil)
-		currentScript := currentCallStack[1].SrcName()
-		relativePath := path.Join(path.Dir(currentScript), scriptPath)
-		script, err = e.readFile(relativePath)
+	currentScript := callerFile(call)
+
+	resolved := e.resolveRef(scriptPath, currentScript)
+
+	script, err := e.readFile(resolved)
+	if err == nil {
+		scriptPath = resolved
+	} else {
+		// Fall back to resolving the path as given through the search locations, and then relative to the calling script
+		script, err = e.readFileFrom(scriptPath, path.Dir(currentScript))
 	}
 	if err != nil {
 		panic(vm.NewGoError(err))
@@ -470,9 +905,10 @@ func (e *Engine) registerTemplateFunc(call CallContext) goja.Value {
 		panic(call.VM.NewGoError(fmt.Errorf("%w: second argument must be a function", ErrInvalidArg)))
 	}
 
-	if _, ok := e.templator.TmplFuncs[name]; ok {
+	if e.isReservedTemplateFunc(name) {
 		panic(call.VM.NewGoError(fmt.Errorf("%w: template function %s already exists", ErrReserved, name)))
 	}
+	delete(e.builtinTmplFuncs, name)
 
 	e.templator.TmplFuncs[name] = func(fn goja.Callable) func(args ...interface{}) any {
 		return func(args ...interface{}) any {
@@ -486,6 +922,10 @@ func (e *Engine) registerTemplateFunc(call CallContext) goja.Value {
 				panic(err)
 			}
 
+			if e.templator.Strict && goja.IsUndefined(val) {
+				panic(fmt.Errorf("%w: template function %s returned undefined", ErrUndefinedValue, name))
+			}
+
 			return val.Export()
 		}
 	}(fn)
@@ -556,27 +996,56 @@ func recoverGoRuntimePanic(v *vm.VM) {
 	}))
 }
 
-func (e *Engine) readFile(file string) ([]byte, error) {
-	filePath := file
+func (e *Engine) loadPartials() error {
+	if len(e.partialDirs) == 0 {
+		return nil
+	}
+
+	partials := map[string]string{}
 
-	for _, dir := range e.searchLocations {
-		searchPath := path.Join(dir, filePath)
+	for _, dir := range e.partialDirs {
+		root := path.Clean(e.resolvePath(dir))
 
-		if e.readFS != nil {
-			if _, err := fs.Stat(e.readFS, searchPath); err == nil {
-				filePath = searchPath
-				break
+		err := e.walkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
 			}
-		} else {
-			if _, err := os.Stat(searchPath); err == nil {
-				filePath = searchPath
-				break
+			if d.IsDir() || path.Ext(filePath) != templateExt {
+				return nil
 			}
+
+			data, err := e.readFile(filePath)
+			if err != nil {
+				return err
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(filePath, root+"/"), templateExt)
+			partials[name] = string(data)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to load partials from %s: %w", dir, err)
 		}
 	}
 
+	e.templator.Partials = partials
+
+	return nil
+}
+
+// statFile returns the FileInfo for the path from the read file system if set, or from disk otherwise.
+func (e *Engine) statFile(filePath string) (fs.FileInfo, error) {
+	if e.readFS != nil {
+		return fs.Stat(e.readFS, filePath)
+	}
+	return os.Stat(filePath)
+}
+
+// walkDir walks the directory tree rooted at root in the read file system if set, or on disk otherwise.
+func (e *Engine) walkDir(root string, fn fs.WalkDirFunc) error {
 	if e.readFS != nil {
-		return fs.ReadFile(e.readFS, filePath)
+		return fs.WalkDir(e.readFS, root, fn)
 	}
-	return os.ReadFile(filePath)
+	return filepath.WalkDir(root, fn)
 }
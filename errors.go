@@ -0,0 +1,113 @@
+package easytemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorMode determines how the engine handles templates that fail to render.
+type ErrorMode int
+
+const (
+	// FailFast aborts the current run on the first failing templateFile call. This is the default.
+	FailFast ErrorMode = iota
+	// CollectErrors records failing templateFile calls and continues the run, the failing call produces no output.
+	// The recorded failures are returned as a *MultiError by the engine method that started the run (ie RunScript or TemplateFile) once it completes.
+	CollectErrors
+)
+
+// TemplateError describes a single templateFile call that failed while running in CollectErrors mode.
+type TemplateError struct {
+	// TemplateFile is the template that failed to render.
+	TemplateFile string
+	// OutFile is the output file the template would have been written to.
+	OutFile string
+	// Location is the position of the call to templateFile within the calling script, if it was called from JS.
+	Location string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Location != "" {
+		return fmt.Sprintf("%s: failed to template %s to %s: %s", e.Location, e.TemplateFile, e.OutFile, e.Err.Error())
+	}
+
+	return fmt.Sprintf("failed to template %s to %s: %s", e.TemplateFile, e.OutFile, e.Err.Error())
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError is returned when running in CollectErrors mode and one or more errors occurred during the run.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d error(s) occurred:", len(e.Errors))
+	for _, err := range e.Errors {
+		sb.WriteString("\n\t* ")
+		sb.WriteString(strings.ReplaceAll(err.Error(), "\n", "\n\t  "))
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the collected errors, allowing errors.Is and errors.As to match any of them.
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+func (e *Engine) collectError(templateFile, outFile, location string, err error) {
+	e.collectedErrors = append(e.collectedErrors, &TemplateError{
+		TemplateFile: templateFile,
+		OutFile:      outFile,
+		Location:     location,
+		Err:          err,
+	})
+}
+
+// collectErrors starts collecting errors for a call to one of the engine's public entry points.
+// The returned function combines the errors collected during the call with the provided error (which may be nil),
+// and restores any collection already in progress so a reentrant call only reports its own failures.
+func (e *Engine) collectErrors() func(err error) error {
+	outer := e.collectedErrors
+	e.collectedErrors = nil
+
+	return func(err error) error {
+		collected := e.collectedErrors
+		e.collectedErrors = outer
+
+		if len(collected) == 0 {
+			return err
+		}
+
+		errs := make([]error, 0, len(collected)+1)
+		for _, c := range collected {
+			errs = append(errs, c)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		return &MultiError{Errors: errs}
+	}
+}
+
+func callerLocation(call CallContext) string {
+	frames := call.VM.CaptureCallStack(0, nil)
+	for _, frame := range frames {
+		pos := frame.Position()
+		if pos.Filename == "" {
+			continue
+		}
+
+		return fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column)
+	}
+
+	return ""
+}
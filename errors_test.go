@@ -0,0 +1,113 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_CollectErrors(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithErrorMode(easytemplate.CollectErrors),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"main.js":      {Data: []byte("templateFile(\"good.stmpl\", \"good.txt\", {});\ntemplateFile(\"bad.stmpl\", \"bad1.txt\", {});\ntemplateFile(\"nested.stmpl\", \"nested.txt\", {});\n")},
+			"good.stmpl":   {Data: []byte("good")},
+			"bad.stmpl":    {Data: []byte("{{ .Local.Missing.Field }")},
+			"nested.stmpl": {Data: []byte("nested{{ templateFile \"bad.stmpl\" \"bad2.txt\" .Local }}")},
+		}),
+		easytemplate.WithWriteFunc(func(outFile string, data []byte) error {
+			written[outFile] = string(data)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.Error(t, err)
+
+	var multiErr *easytemplate.MultiError
+	require.ErrorAs(t, err, &multiErr)
+	require.Len(t, multiErr.Errors, 2)
+
+	var tmplErr *easytemplate.TemplateError
+	require.ErrorAs(t, multiErr.Errors[0], &tmplErr)
+	assert.Equal(t, "bad.stmpl", tmplErr.TemplateFile)
+	assert.Equal(t, "bad1.txt", tmplErr.OutFile)
+	assert.Equal(t, "main.js:2:13", tmplErr.Location)
+
+	require.ErrorAs(t, multiErr.Errors[1], &tmplErr)
+	assert.Equal(t, "bad2.txt", tmplErr.OutFile)
+
+	assert.Equal(t, map[string]string{"good.txt": "good", "nested.txt": "nested"}, written)
+}
+
+func TestEngine_TemplateString_CollectErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		render     func(e *easytemplate.Engine) error
+		wantErrors int
+	}{
+		{
+			name: "templateFile nested in a template",
+			render: func(e *easytemplate.Engine) error {
+				_, err := e.TemplateString(context.Background(), "nested.stmpl", nil)
+				return err
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "templateFile called by a function",
+			render: func(e *easytemplate.Engine) error {
+				_, err := e.RunFunction(context.Background(), "run")
+				return err
+			},
+			wantErrors: 1,
+		},
+		{
+			name: "no failures",
+			render: func(e *easytemplate.Engine) error {
+				_, err := e.TemplateStringInput(context.Background(), "good", "good", nil)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(
+				easytemplate.WithErrorMode(easytemplate.CollectErrors),
+				easytemplate.WithReadFileSystem(fstest.MapFS{
+					"main.js":      {Data: []byte("function run() { templateFile(\"bad.stmpl\", \"bad.txt\", {}); }\n")},
+					"bad.stmpl":    {Data: []byte("{{ .Local.Missing.Field }")},
+					"nested.stmpl": {Data: []byte("nested{{ templateFile \"bad.stmpl\" \"bad.txt\" .Local }}")},
+				}),
+				easytemplate.WithWriteFunc(func(outFile string, data []byte) error {
+					return nil
+				}),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			err = e.RunScript(context.Background(), "main.js")
+			require.NoError(t, err)
+
+			err = tt.render(e)
+			if tt.wantErrors == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var multiErr *easytemplate.MultiError
+			require.ErrorAs(t, err, &multiErr)
+			assert.Len(t, multiErr.Errors, tt.wantErrors)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package easytemplate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate/internal/utils"
+)
+
+// fileFuncs returns the functions providing raw file access, exposed to js as methods of the global fs object.
+// As with all other reads by the engine, files are read from the read file system if set (which limits access to it) or disk otherwise,
+// and relative paths are resolved through the search locations.
+func (e *Engine) fileFuncs() map[string]any {
+	return map[string]any{
+		"readFile": e.readFileString,
+		"exists":   e.fileExists,
+		"glob":     e.glob,
+		"readDir":  e.readDir,
+	}
+}
+
+// fileTmplFuncs returns the template function equivalents of the fs functions.
+func (e *Engine) fileTmplFuncs() map[string]any {
+	return map[string]any{
+		"readFile":   e.readFileString,
+		"fileExists": e.fileExists,
+		"glob":       e.glob,
+	}
+}
+
+func (e *Engine) readFileString(file string) (string, error) {
+	data, err := e.readFile(e.resolveLoaderRef(file))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	return string(data), nil
+}
+
+func (e *Engine) fileExists(file string) bool {
+	_, err := e.statFile(e.resolvePath(e.resolveLoaderRef(file)))
+	return err == nil
+}
+
+// glob returns the sorted paths of the files matching the pattern, searched for relative to each of the search locations,
+// or the current directory if there are none. Patterns use path.Match syntax with the addition of ** matching any number of directories.
+// When reading from disk without any search locations the pattern must start with a directory, so the whole working directory isn't walked.
+func (e *Engine) glob(pattern string) ([]string, error) {
+	pattern = path.Clean(pattern)
+
+	dirs := e.searchLocations
+	if len(dirs) == 0 {
+		if e.readFS == nil && globRoot(pattern) == "." {
+			return nil, fmt.Errorf("%w: glob pattern %s must start with a directory when reading from disk", ErrInvalidArg, pattern)
+		}
+
+		dirs = []string{""}
+	}
+
+	seen := map[string]struct{}{}
+	matches := []string{}
+
+	for _, dir := range dirs {
+		full := path.Join(dir, pattern)
+
+		err := e.walkDir(globRoot(full), func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipDir
+				}
+				return err
+			}
+
+			filePath = filepath.ToSlash(filePath)
+			if d.IsDir() || !utils.MatchGlob(full, filePath) {
+				return nil
+			}
+
+			if _, ok := seen[filePath]; !ok {
+				seen[filePath] = struct{}{}
+				matches = append(matches, filePath)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob %s: %w", pattern, err)
+		}
+	}
+
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// globRoot returns the directory containing everything the pattern can match, the pattern's longest prefix without any wildcards.
+func globRoot(pattern string) string {
+	segments := strings.Split(pattern, "/")
+
+	root := []string{}
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.ContainsAny(segment, "*?[\\") {
+			break
+		}
+		root = append(root, segment)
+	}
+
+	if len(root) == 0 {
+		return "."
+	}
+
+	return strings.Join(root, "/")
+}
+
+// readDir returns the entries of the directory as objects with name, path and isDir properties, sorted by name.
+func (e *Engine) readDir(dir string) ([]map[string]any, error) {
+	dirPath := e.resolvePath(e.resolveLoaderRef(dir))
+
+	var entries []fs.DirEntry
+	var err error
+
+	if e.readFS != nil {
+		entries, err = fs.ReadDir(e.readFS, dirPath)
+	} else {
+		entries, err = os.ReadDir(dirPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir %s: %w", dir, err)
+	}
+
+	out := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, map[string]any{
+			"name":  entry.Name(),
+			"path":  path.Join(dirPath, entry.Name()),
+			"isDir": entry.IsDir(),
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_FileAccess(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithFileAccess(),
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"main.js": {Data: []byte(`
+const models = fs.glob("models/**/*.stmpl");
+const entries = fs.readDir("models").map(e => e.name + (e.isDir ? "/" : ""));
+const override = fs.exists("overrides/user.stmpl") ? "overrides/user.stmpl" : "models/user.stmpl";
+templateFile("index.stmpl", "index.txt", {models, entries, override, header: fs.readFile("header.txt")});
+`)},
+			"index.stmpl":                     {Data: []byte("{{ .Local.header }}{{ range .Local.models }}{{ . }},{{ end }} {{ .Local.entries }} {{ .Local.override }} {{ fileExists \"header.txt\" }} {{ fileExists \"missing.txt\" }} {{ glob \"models/*.stmpl\" }}")},
+			"templates/header.txt":            {Data: []byte("header ")},
+			"templates/models/user.stmpl":     {Data: []byte("user")},
+			"templates/models/nested/a.stmpl": {Data: []byte("a")},
+			"templates/models/nested/readme":  {Data: []byte("readme")},
+			"templates/overrides/user.stmpl":  {Data: []byte("override")},
+		}),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+	assert.Equal(t, "header templates/models/nested/a.stmpl,templates/models/user.stmpl, [nested/ user.stmpl] overrides/user.stmpl true false [templates/models/user.stmpl]", written["index.txt"])
+}
+
+func TestEngine_TemplateStringInput_GlobOnDisk(t *testing.T) {
+	e := easytemplate.New(easytemplate.WithFileAccess())
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	out, err := e.TemplateStringInput(context.Background(), "test", "{{ glob \"testdata/templates/test?.stmpl\" }}", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[testdata/templates/test2.stmpl testdata/templates/test3.stmpl testdata/templates/test4.stmpl testdata/templates/test5.stmpl]", out)
+
+	_, err = e.TemplateStringInput(context.Background(), "test", "{{ glob \"**/*.stmpl\" }}", nil)
+	assert.ErrorContains(t, err, "glob pattern **/*.stmpl must start with a directory")
+}
+
+func TestEngine_WithJSFuncs_FS(t *testing.T) {
+	e := easytemplate.New(easytemplate.WithJSFuncs(map[string]func(call easytemplate.CallContext) goja.Value{
+		"fs": func(call easytemplate.CallContext) goja.Value { return call.VM.ToValue("custom") },
+	}))
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	out, err := e.TemplateStringInput(context.Background(), "test", "```sjs\nrender(fs());\nsjs```", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", out)
+}
+
+func TestEngine_TemplateStringInput_FileAccessDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "readFile", input: `{{ readFile "header.txt" }}`, wantErr: `function "readFile" not defined`},
+		{name: "fileExists", input: `{{ fileExists "header.txt" }}`, wantErr: `function "fileExists" not defined`},
+		{name: "glob", input: `{{ glob "*.txt" }}`, wantErr: `function "glob" not defined`},
+		{name: "fs", input: "```sjs\nrender(fs.readFile(\"header.txt\"));\nsjs```", wantErr: "fs is not defined"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(easytemplate.WithReadFileSystem(fstest.MapFS{
+				"header.txt": {Data: []byte("header")},
+			}))
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			_, err = e.TemplateStringInput(context.Background(), "test", tt.input, nil)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package easytemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate/internal/template"
+)
+
+// Formatter formats the rendered output of a template before it is written, see WithFormatter.
+type Formatter = template.Formatter
+
+// FormatError is returned when a Formatter fails to format the output of a template.
+// It identifies the template that rendered the output and, where the formatter reports one, the number and text of the offending
+// line of the rendered output. Line numbers refer to the output rather than the template, as the two rarely line up once actions are executed.
+type FormatError = template.FormatError
+
+// FormatterFunc is an adapter allowing an ordinary function to be used as a Formatter.
+type FormatterFunc func(path string, src []byte) ([]byte, error)
+
+// Format calls f(path, src).
+func (f FormatterFunc) Format(path string, src []byte) ([]byte, error) {
+	return f(path, src)
+}
+
+// GoFormatter returns a Formatter that formats Go source with go/format, equivalent to gofmt.
+func GoFormatter() Formatter {
+	return FormatterFunc(func(_ string, src []byte) ([]byte, error) {
+		return format.Source(src)
+	})
+}
+
+// JSONFormatter returns a Formatter that pretty-prints JSON, indenting with two spaces and ending with a newline.
+func JSONFormatter() Formatter {
+	return FormatterFunc(func(_ string, src []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, src, "", "  "); err != nil {
+			return nil, err
+		}
+
+		return append(bytes.TrimRight(buf.Bytes(), " \t\r\n"), '\n'), nil
+	})
+}
+
+// WithFormatter registers a Formatter run on the output of templateFile (and TemplateDir) for files with the provided extension (ie ".go"),
+// before the output is written. Registering a formatter for an extension replaces any previously registered for it.
+//
+// Example:
+//
+//	e := easytemplate.New(
+//		easytemplate.WithFormatter(".go", easytemplate.GoFormatter()),
+//		easytemplate.WithFormatter(".json", easytemplate.JSONFormatter()),
+//	)
+func WithFormatter(ext string, formatter Formatter) Opt {
+	return func(e *Engine) {
+		if e.templator.Formatters == nil {
+			e.templator.Formatters = map[string]Formatter{}
+		}
+
+		e.templator.Formatters[strings.ToLower(ext)] = formatter
+	}
+}
@@ -0,0 +1,88 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFormattingEngine(t *testing.T, written map[string]string) *easytemplate.Engine {
+	t.Helper()
+
+	e := easytemplate.New(
+		easytemplate.WithFormatter(".go", easytemplate.GoFormatter()),
+		easytemplate.WithFormatter(".json", easytemplate.JSONFormatter()),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"main.stmpl":   {Data: []byte("package main\nfunc {{ .Local.name }}( ) {\n}")},
+			"data.stmpl":   {Data: []byte("{\"name\":\"{{ .Local.name }}\",\"list\":[1,2]}")},
+			"broken.stmpl": {Data: []byte("package main\n\nfunc {{ .Local.name }}( {\n}")},
+		}),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	return e
+}
+
+func TestEngine_TemplateFile_Formatters(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		outFile  string
+		wantOut  string
+	}{
+		{
+			name:     "go",
+			template: "main.stmpl",
+			outFile:  "main.go",
+			wantOut:  "package main\n\nfunc run() {\n}\n",
+		},
+		{
+			name:     "json",
+			template: "data.stmpl",
+			outFile:  "data.json",
+			wantOut:  "{\n  \"name\": \"run\",\n  \"list\": [\n    1,\n    2\n  ]\n}\n",
+		},
+		{
+			name:     "no formatter for extension",
+			template: "main.stmpl",
+			outFile:  "main.txt",
+			wantOut:  "package main\nfunc run( ) {\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := map[string]string{}
+			e := newFormattingEngine(t, written)
+
+			err := e.TemplateFile(context.Background(), tt.template, tt.outFile, map[string]any{"name": "run"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, written[tt.outFile])
+		})
+	}
+}
+
+func TestEngine_TemplateFile_FormatError(t *testing.T) {
+	written := map[string]string{}
+	e := newFormattingEngine(t, written)
+
+	err := e.TemplateFile(context.Background(), "broken.stmpl", "broken.go", map[string]any{"name": "run"})
+	require.Error(t, err)
+
+	var formatErr *easytemplate.FormatError
+	require.ErrorAs(t, err, &formatErr)
+	assert.Equal(t, "broken.stmpl", formatErr.TemplateFile)
+	assert.Equal(t, "broken.go", formatErr.OutFile)
+	assert.Equal(t, 3, formatErr.OutputLine)
+	assert.Equal(t, "func run( {", formatErr.Source)
+	assert.NotContains(t, written, "broken.go")
+}
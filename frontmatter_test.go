@@ -0,0 +1,60 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_FrontMatter(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithFrontMatter(),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"main.js": {Data: []byte(`templateFile("model.stmpl", { name: "user" });
+templateFile("model.stmpl", "override.txt", { name: "other", greeting: "Hi" });
+templateFile("disabled.stmpl", "disabled.txt", {});
+templateFile("toml.stmpl", "toml.txt", {});
+`)},
+			"model.stmpl": {Data: []byte(`---
+output: "{{ .Local.name }}.txt"
+description: A model
+required: [name]
+local:
+  greeting: Hello
+---
+{{ .Local.greeting }} {{ .Local.name }} ({{ .Meta.Description }})
+` + "```sjs\nrender(context.Meta.Output);\nsjs```")},
+			"disabled.stmpl": {Data: []byte("---\nenabled: \"{{ .Local.enabled }}\"\n---\ndisabled")},
+			"toml.stmpl":     {Data: []byte("+++\nrecurse = 1\n+++\n{{ \"{{ .Meta.Recurse }}\" }}")},
+		}),
+		easytemplate.WithWriteFunc(func(outFile string, data []byte) error {
+			written[outFile] = string(data)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"user.txt":     "Hello user (A model)\nuser.txt",
+		"override.txt": "Hi other (A model)\nother.txt",
+		"toml.txt":     "1",
+	}, written)
+
+	_, err = e.TemplateString(context.Background(), "model.stmpl", map[string]any{})
+	assert.EqualError(t, err, "template model.stmpl requires data key name")
+
+	err = e.TemplateFile(context.Background(), "toml.stmpl", "", nil)
+	require.ErrorIs(t, err, easytemplate.ErrNoOutputFile)
+	assert.NotContains(t, written, "")
+}
@@ -0,0 +1,487 @@
+// Package httpserve provides an http.Handler rendering templates with easytemplate, for example to serve previews of generated code.
+//
+// Request paths are mapped to templates read through the engine's read file system, ie with the default template extension
+// a request for /models/user.go renders the template models/user.go.stmpl. The data for the template (available as .Local in templates
+// and context.Local in scripts) is the JSON body of POST requests or the query parameters of GET requests.
+package httpserve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/speakeasy-api/easytemplate"
+)
+
+const (
+	defaultTemplateExt = ".stmpl"
+	defaultIndex       = "index.html"
+	defaultContentType = "text/plain; charset=utf-8"
+	defaultMaxBodySize = 1 << 20
+)
+
+// defaultContentTypes are the content types used for common output extensions, in preference to the system's mime types
+// which for code (ie .ts) are often wrong.
+var defaultContentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".htm":  "text/html; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".mjs":  "text/javascript; charset=utf-8",
+	".json": "application/json",
+	".xml":  "application/xml; charset=utf-8",
+	".svg":  "image/svg+xml",
+	".yaml": "application/yaml; charset=utf-8",
+	".yml":  "application/yaml; charset=utf-8",
+	".toml": "application/toml; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".txt":  defaultContentType,
+	".go":   defaultContentType,
+	".ts":   defaultContentType,
+	".tsx":  defaultContentType,
+	".py":   defaultContentType,
+	".java": defaultContentType,
+	".kt":   defaultContentType,
+	".cs":   defaultContentType,
+	".rb":   defaultContentType,
+	".php":  defaultContentType,
+	".rs":   defaultContentType,
+	".sh":   defaultContentType,
+}
+
+// notFoundDetailsRegex matches the paths tried and suggestions listed in the message of an easytemplate.NotFoundError.
+var notFoundDetailsRegex = regexp.MustCompile(`(` + regexp.QuoteMeta(easytemplate.ErrTemplateNotFound.Error()) + `), tried: [^;\n]*(?:; did you mean [^?\n]*\?)?`)
+
+// Opt is a function that configures the Handler.
+type Opt func(*Handler)
+
+// WithEngineOptions sets the options each engine used to render requests is created with, including where templates are read from.
+func WithEngineOptions(opts ...easytemplate.Opt) Opt {
+	return func(h *Handler) {
+		h.engineOpts = append(h.engineOpts, opts...)
+	}
+}
+
+// WithGlobalData sets the global data each engine is initialized with, available as .Global in templates and context.Global in scripts.
+func WithGlobalData(data any) Opt {
+	return func(h *Handler) {
+		h.globalData = data
+	}
+}
+
+// WithSetup sets a function called with each engine once it's initialized, before it renders any requests. This can be used
+// to prepare the environment, ie by running a script registering template functions with RunScript.
+func WithSetup(setup func(ctx context.Context, e *easytemplate.Engine) error) Opt {
+	return func(h *Handler) {
+		h.setup = setup
+	}
+}
+
+// WithTemplateExt sets the extension appended to request paths to find the template to render, defaulting to .stmpl.
+// An empty extension maps request paths directly to templates.
+func WithTemplateExt(ext string) Opt {
+	return func(h *Handler) {
+		h.templateExt = ext
+	}
+}
+
+// WithIndex sets the file rendered for requests for a directory, defaulting to index.html (ie the template index.html.stmpl).
+func WithIndex(name string) Opt {
+	return func(h *Handler) {
+		h.index = name
+	}
+}
+
+// WithContentType sets the content type of responses for request paths with the given extension (ie ".go"), overriding the default.
+func WithContentType(ext, contentType string) Opt {
+	return func(h *Handler) {
+		h.contentTypes[strings.ToLower(ext)] = contentType
+	}
+}
+
+// WithPoolSize sets the number of initialized engines kept ready to render requests, defaulting to GOMAXPROCS.
+// A size of 0 creates each engine when the request is received.
+func WithPoolSize(size int) Opt {
+	return func(h *Handler) {
+		h.poolSize = size
+	}
+}
+
+// WithMaxBodySize sets the maximum size in bytes of the body of POST requests, defaulting to 1MB. Larger requests fail
+// with a 413 (Request Entity Too Large) response.
+func WithMaxBodySize(size int64) Opt {
+	return func(h *Handler) {
+		h.maxBodySize = size
+	}
+}
+
+// WithErrorDetails includes the paths tried and similarly named templates in the response when a template can't be found
+// (see Error). They're left out by default as they reveal the layout of the server's files, and are only logged (see WithErrorLog).
+func WithErrorDetails() Opt {
+	return func(h *Handler) {
+		h.errorDetails = true
+	}
+}
+
+// WithErrorLog sets the logger failed requests are logged to with their full error, defaulting to the standard logger.
+func WithErrorLog(l *log.Logger) Opt {
+	return func(h *Handler) {
+		h.errorLog = l
+	}
+}
+
+// Handler is an http.Handler rendering the template matching each request's path.
+//
+// Each request is rendered by its own engine, which is closed once the request is rendered, so changes a template makes to its
+// environment (ie registering template functions) are never seen by other requests. To avoid initializing an engine while
+// handling a request, a pool of engines ready to render (see WithPoolSize) is refilled in the background by a single goroutine
+// as engines are used. Call Close to stop it and close the engines remaining in the pool once the handler is no longer used.
+type Handler struct {
+	engineOpts   []easytemplate.Opt
+	globalData   any
+	setup        func(ctx context.Context, e *easytemplate.Engine) error
+	templateExt  string
+	index        string
+	contentTypes map[string]string
+	poolSize     int
+	maxBodySize  int64
+	errorDetails bool
+	errorLog     *log.Logger
+
+	pool        chan *easytemplate.Engine
+	poolMu      sync.Mutex
+	isClosed    bool
+	refillStart sync.Once
+	refillReq   chan struct{}
+	done        chan struct{}
+	refilling   sync.WaitGroup
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// New creates a new Handler with the provided options.
+//
+// Example:
+//
+//	h := httpserve.New(httpserve.WithEngineOptions(easytemplate.WithReadFileSystem(templates)))
+//	http.Handle("/preview/", http.StripPrefix("/preview", h))
+func New(opts ...Opt) *Handler {
+	h := &Handler{
+		templateExt:  defaultTemplateExt,
+		index:        defaultIndex,
+		contentTypes: map[string]string{},
+		poolSize:     runtime.GOMAXPROCS(0),
+		maxBodySize:  defaultMaxBodySize,
+		errorLog:     log.Default(),
+		refillReq:    make(chan struct{}, 1),
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.pool = make(chan *easytemplate.Engine, h.poolSize)
+
+	return h
+}
+
+// Close stops refilling the pool and closes the engines remaining in it. Requests handled after Close create their engines as
+// they're received.
+func (h *Handler) Close() error {
+	h.poolMu.Lock()
+	if !h.isClosed {
+		h.isClosed = true
+		close(h.done)
+	}
+	h.poolMu.Unlock()
+
+	// Any engine being created for the pool is closed by put
+	h.refilling.Wait()
+
+	var errs []error
+	for {
+		select {
+		case e := <-h.pool:
+			if err := e.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// ErrorResponse is the body of the JSON response returned when a request fails.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// Error describes why a request failed.
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// Message describes the error.
+	Message string `json:"message"`
+	// Template is the template the request was mapped to, if any.
+	Template string `json:"template,omitempty"`
+	// Tried lists the paths tried when the template couldn't be found, if enabled with WithErrorDetails.
+	Tried []string `json:"tried,omitempty"`
+	// Suggestions lists templates with names similar to the template when it couldn't be found, if enabled with WithErrorDetails.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ServeHTTP renders the template matching the request's path, with the request's data as the template's local data.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		writeError(w, Error{Status: http.StatusMethodNotAllowed, Message: fmt.Sprintf("method %s not allowed", r.Method)})
+		return
+	}
+
+	outFile := h.outFile(r.URL.Path)
+	templatePath := outFile + h.templateExt
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize)
+
+	data, err := requestData(r)
+	if err != nil {
+		status := http.StatusBadRequest
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		writeError(w, Error{Status: status, Message: err.Error(), Template: templatePath})
+		return
+	}
+
+	e, err := h.getEngine()
+	if err != nil {
+		writeError(w, Error{Status: http.StatusInternalServerError, Message: err.Error(), Template: templatePath})
+		return
+	}
+
+	// The engine is only used for this request, so any state it's left with isn't seen by later requests
+	defer func() { _ = e.Close() }()
+	h.requestRefill()
+
+	// The output is buffered so a failure can still be reported with a structured error
+	var buf bytes.Buffer
+
+	if err := e.TemplateTo(r.Context(), &buf, templatePath, data); err != nil {
+		h.errorLog.Printf("httpserve: failed to render %s: %v", templatePath, err)
+
+		writeError(w, h.templateError(templatePath, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", h.contentType(outFile))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// outFile returns the output file the request path maps to.
+func (h *Handler) outFile(urlPath string) string {
+	outFile := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+
+	if outFile == "" || strings.HasSuffix(urlPath, "/") {
+		outFile = path.Join(outFile, h.index)
+	}
+
+	return outFile
+}
+
+func (h *Handler) contentType(outFile string) string {
+	ext := strings.ToLower(path.Ext(outFile))
+
+	if contentType, ok := h.contentTypes[ext]; ok {
+		return contentType
+	}
+
+	if contentType, ok := defaultContentTypes[ext]; ok {
+		return contentType
+	}
+
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+
+	return defaultContentType
+}
+
+// getEngine returns an engine from the pool, or creates one if the pool is empty.
+func (h *Handler) getEngine() (*easytemplate.Engine, error) {
+	select {
+	case e := <-h.pool:
+		return e, nil
+	default:
+		return h.newEngine()
+	}
+}
+
+// requestRefill asks the refill goroutine, started with the first request, to top up the pool. Requests made while it's
+// already refilling are merged into one.
+func (h *Handler) requestRefill() {
+	if h.poolSize == 0 {
+		return
+	}
+
+	h.refillStart.Do(func() {
+		h.poolMu.Lock()
+		defer h.poolMu.Unlock()
+
+		if !h.isClosed {
+			h.refilling.Add(1)
+			go h.refill()
+		}
+	})
+
+	select {
+	case h.refillReq <- struct{}{}:
+	default:
+	}
+}
+
+// refill creates engines until the pool is full each time a refill is requested, until the handler is closed.
+func (h *Handler) refill() {
+	defer h.refilling.Done()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-h.refillReq:
+		}
+
+		for h.needsEngine() {
+			e, err := h.newEngine()
+			if err != nil {
+				// The error is reported by the next request creating its own engine
+				break
+			}
+
+			h.put(e)
+		}
+	}
+}
+
+func (h *Handler) needsEngine() bool {
+	h.poolMu.Lock()
+	defer h.poolMu.Unlock()
+
+	return !h.isClosed && len(h.pool) < h.poolSize
+}
+
+// put adds the engine to the pool, closing it instead if the handler was closed in the meantime.
+func (h *Handler) put(e *easytemplate.Engine) {
+	h.poolMu.Lock()
+	defer h.poolMu.Unlock()
+
+	if h.isClosed {
+		_ = e.Close()
+		return
+	}
+
+	select {
+	case h.pool <- e:
+	default:
+		_ = e.Close()
+	}
+}
+
+func (h *Handler) newEngine() (*easytemplate.Engine, error) {
+	// Pooled engines outlive the request that created them, so aren't initialized with its context
+	ctx := context.Background()
+
+	e := easytemplate.New(h.engineOpts...)
+
+	if err := e.Init(ctx, h.globalData); err != nil {
+		return nil, fmt.Errorf("failed to initialize engine: %w", err)
+	}
+
+	if h.setup != nil {
+		if err := h.setup(ctx, e); err != nil {
+			_ = e.Close()
+			return nil, fmt.Errorf("failed to set up engine: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// requestData returns the data provided by the request, the decoded JSON body of POST requests (nil if empty) or the query parameters otherwise.
+// Query parameters with a single value are strings, those repeated are lists of strings.
+func requestData(r *http.Request) (any, error) {
+	if r.Method == http.MethodPost {
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != "" && contentType != "application/json" {
+			return nil, fmt.Errorf("unsupported content type %q, expected application/json", contentType)
+		}
+
+		var data any
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to decode request body: %w", err)
+		}
+
+		return data, nil
+	}
+
+	data := map[string]any{}
+	for key, values := range r.URL.Query() {
+		if len(values) == 1 {
+			data[key] = values[0]
+		} else {
+			data[key] = values
+		}
+	}
+
+	return data, nil
+}
+
+func (h *Handler) templateError(templatePath string, err error) Error {
+	res := Error{Status: http.StatusInternalServerError, Message: err.Error(), Template: templatePath}
+
+	if !h.errorDetails {
+		// Not found errors list the paths tried and similarly named files, including those wrapped in messages from templates and scripts
+		res.Message = notFoundDetailsRegex.ReplaceAllString(res.Message, "$1")
+	}
+
+	var notFound *easytemplate.NotFoundError
+	if !errors.As(err, &notFound) {
+		return res
+	}
+
+	// Only a missing requested template is a 404, not one referenced while rendering it
+	if notFound.Name == templatePath {
+		res.Status = http.StatusNotFound
+	}
+
+	if h.errorDetails {
+		res.Tried = notFound.Tried
+		res.Suggestions = notFound.Suggestions()
+	}
+
+	return res
+}
+
+func writeError(w http.ResponseWriter, err Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.Status)
+
+	_ = json.NewEncoder(w).Encode(ErrorResponse{Error: err})
+}
@@ -0,0 +1,265 @@
+package httpserve_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/speakeasy-api/easytemplate/httpserve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var readFS = fstest.MapFS{
+	"index.html.stmpl":       {Data: []byte("<h1>{{ .Global.title }}</h1>")},
+	"models/user.go.stmpl":   {Data: []byte("type {{ .Local.name }} struct{}")},
+	"models/user.ts.stmpl":   {Data: []byte("```sjs\nrender(`export class ${context.Local.name} {}`);\nsjs```")},
+	"broken.txt.stmpl":       {Data: []byte("{{ templateString \"missing.stmpl\" . }}")},
+	"setup.js":               {Data: []byte("registerTemplateFunc(\"shout\", function(s) { return s.toUpperCase(); });")},
+	"models/shout.txt.stmpl": {Data: []byte("{{ shout .Local.name }}")},
+	"register.txt.stmpl":     {Data: []byte("```sjs\nregisterTemplateFunc(\"leak\", function() { return \"leaked\"; });\nsjs```\nregistered")},
+	"leak.txt.stmpl":         {Data: []byte("{{ leak }}")},
+}
+
+func newHandler(t *testing.T, opts ...httpserve.Opt) *httpserve.Handler {
+	t.Helper()
+
+	h := httpserve.New(append([]httpserve.Opt{
+		httpserve.WithEngineOptions(easytemplate.WithReadFileSystem(readFS)),
+		httpserve.WithGlobalData(map[string]any{"title": "Preview"}),
+		httpserve.WithSetup(func(ctx context.Context, e *easytemplate.Engine) error {
+			return e.RunScript(ctx, "setup.js")
+		}),
+	}, opts...)...)
+	t.Cleanup(func() { require.NoError(t, h.Close()) })
+
+	return h
+}
+
+func TestHandler_Render(t *testing.T) {
+	h := newHandler(t)
+
+	tests := []struct {
+		name            string
+		req             *http.Request
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			name:            "index with global data",
+			req:             httptest.NewRequest(http.MethodGet, "/", nil),
+			wantBody:        "<h1>Preview</h1>",
+			wantContentType: "text/html; charset=utf-8",
+		},
+		{
+			name:            "query params as local data",
+			req:             httptest.NewRequest(http.MethodGet, "/models/user.go?name=User", nil),
+			wantBody:        "type User struct{}",
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:            "json body as local data",
+			req:             jsonRequest("/models/user.ts", `{"name": "Pet"}`),
+			wantBody:        "export class Pet {}",
+			wantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:            "template funcs registered by setup",
+			req:             httptest.NewRequest(http.MethodGet, "/models/shout.txt?name=hi", nil),
+			wantBody:        "HI",
+			wantContentType: "text/plain; charset=utf-8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	h := newHandler(t)
+	detailed := newHandler(t, httpserve.WithErrorDetails())
+
+	tests := []struct {
+		name        string
+		handler     *httpserve.Handler
+		req         *http.Request
+		wantStatus  int
+		wantMessage string
+		wantTried   bool
+	}{
+		{
+			name:        "template not found",
+			req:         httptest.NewRequest(http.MethodGet, "/models/usr.go", nil),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "models/usr.go.stmpl: template not found",
+		},
+		{
+			name:        "template not found with details",
+			handler:     detailed,
+			req:         httptest.NewRequest(http.MethodGet, "/models/usr.go", nil),
+			wantStatus:  http.StatusNotFound,
+			wantMessage: "template not found, tried: models/usr.go.stmpl",
+			wantTried:   true,
+		},
+		{
+			name:        "referenced template not found",
+			req:         httptest.NewRequest(http.MethodGet, "/broken.txt", nil),
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "missing.stmpl",
+		},
+		{
+			name:        "invalid json body",
+			req:         jsonRequest("/models/user.ts", `{"name":`),
+			wantStatus:  http.StatusBadRequest,
+			wantMessage: "failed to decode request body",
+		},
+		{
+			name:        "body too large",
+			req:         jsonRequest("/models/user.ts", `{"name": "`+strings.Repeat("a", 2<<20)+`"}`),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantMessage: "request body too large",
+		},
+		{
+			name:        "method not allowed",
+			req:         httptest.NewRequest(http.MethodDelete, "/models/user.go", nil),
+			wantStatus:  http.StatusMethodNotAllowed,
+			wantMessage: "method DELETE not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := h
+			if tt.handler != nil {
+				handler = tt.handler
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var res httpserve.ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+			assert.Equal(t, tt.wantStatus, res.Error.Status)
+			assert.Contains(t, res.Error.Message, tt.wantMessage)
+			if tt.wantTried {
+				assert.Contains(t, res.Error.Tried, "models/usr.go.stmpl")
+				assert.Contains(t, res.Error.Suggestions, "models/user.go.stmpl")
+			} else {
+				assert.Empty(t, res.Error.Tried)
+				assert.Empty(t, res.Error.Suggestions)
+				assert.NotContains(t, res.Error.Message, "tried")
+				assert.NotContains(t, res.Error.Message, "did you mean")
+			}
+		})
+	}
+}
+
+func TestHandler_Concurrent(t *testing.T) {
+	h := newHandler(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, jsonRequest("/models/user.ts", `{"name": "Pet"}`))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "export class Pet {}", rec.Body.String())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHandler_RequestsDontShareState(t *testing.T) {
+	for _, poolSize := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("pool size %d", poolSize), func(t *testing.T) {
+			h := newHandler(t, httpserve.WithPoolSize(poolSize))
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/register.txt", nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "\nregistered", rec.Body.String())
+
+			for i := 0; i < 5; i++ {
+				rec = httptest.NewRecorder()
+				h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leak.txt", nil))
+				assert.Equal(t, http.StatusInternalServerError, rec.Code)
+				assert.Contains(t, rec.Body.String(), `function \"leak\" not defined`)
+			}
+		})
+	}
+}
+
+func TestHandler_PoolRefill(t *testing.T) {
+	var created atomic.Int32
+
+	h := httpserve.New(
+		httpserve.WithEngineOptions(easytemplate.WithReadFileSystem(readFS)),
+		httpserve.WithPoolSize(2),
+		httpserve.WithSetup(func(ctx context.Context, e *easytemplate.Engine) error {
+			created.Add(1)
+			return nil
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/models/user.go?name=User", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// Every engine created was either used by a request, is left in the pool or was being created for the pool when it was closed
+	assert.Eventually(t, func() bool { return created.Load() >= 5+1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, h.Close())
+
+	total := created.Load()
+	assert.LessOrEqual(t, total, int32(5+2+1))
+
+	// Requests after Close still render, without refilling the pool
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/models/user.go?name=User", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, total+1, created.Load())
+}
+
+func TestHandler_WithMaxBodySize(t *testing.T) {
+	h := newHandler(t, httpserve.WithMaxBodySize(16))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, jsonRequest("/models/user.ts", `{"name": "Pet"}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, jsonRequest("/models/user.ts", `{"name": "LongerName"}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func jsonRequest(target, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return req
+}
@@ -0,0 +1,48 @@
+// Package funcs contains the built-in template functions provided by the engine.
+package funcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+)
+
+// Escape returns functions for escaping strings for inclusion in JSON, YAML and XML output.
+func Escape() map[string]any {
+	return map[string]any{
+		"escapeJSON": EscapeJSON,
+		"escapeYAML": EscapeYAML,
+		"escapeXML":  EscapeXML,
+	}
+}
+
+// EscapeJSON escapes the string for use within a double quoted JSON string.
+func EscapeJSON(s string) string {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string can't fail
+	_ = enc.Encode(s)
+
+	out := strings.TrimSuffix(buf.String(), "\n")
+
+	return out[1 : len(out)-1]
+}
+
+// EscapeYAML escapes the string for use within a double quoted YAML string.
+func EscapeYAML(s string) string {
+	// YAML double quoted strings support all of JSON's escape sequences
+	return EscapeJSON(s)
+}
+
+// EscapeXML escapes the string for use within XML text or a quoted attribute value.
+func EscapeXML(s string) string {
+	var buf bytes.Buffer
+
+	// Writing to a bytes.Buffer can't fail
+	_ = xml.EscapeText(&buf, []byte(s))
+
+	return buf.String()
+}
@@ -0,0 +1,16 @@
+package funcs_test
+
+import (
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate/internal/funcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscape(t *testing.T) {
+	in := "say \"hi\" <b>&</b>\n\ttab\\"
+
+	assert.Equal(t, `say \"hi\" <b>&</b>\n\ttab\\`, funcs.EscapeJSON(in))
+	assert.Equal(t, `say \"hi\" <b>&</b>\n\ttab\\`, funcs.EscapeYAML(in))
+	assert.Equal(t, "say &#34;hi&#34; &lt;b&gt;&amp;&lt;/b&gt;&#xA;&#x9;tab\\", funcs.EscapeXML(in))
+}
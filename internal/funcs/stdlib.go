@@ -0,0 +1,275 @@
+package funcs
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Stdlib returns the standard library of case conversion, pluralisation and identifier functions.
+// The functions are plain Go functions, so behave identically whether called from templates or js.
+func Stdlib() map[string]any {
+	return map[string]any{
+		"camelCase":          CamelCase,
+		"pascalCase":         PascalCase,
+		"snakeCase":          SnakeCase,
+		"kebabCase":          KebabCase,
+		"pluralize":          Pluralize,
+		"singularize":        Singularize,
+		"sanitizeIdentifier": SanitizeIdentifier,
+		"goIdentifier":       GoIdentifier,
+		"tsIdentifier":       TSIdentifier,
+		"pyIdentifier":       PyIdentifier,
+	}
+}
+
+// Words splits the string into words, breaking on any character that isn't a letter or digit and on changes of case,
+// keeping acronyms together (ie "HTTPServer_id" -> ["HTTP", "Server", "id"]).
+func Words(s string) []string {
+	words := []string{}
+	runes := []rune(s)
+
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = nil
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if unicode.IsUpper(r) && len(current) > 0 {
+			prev := current[len(current)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if !unicode.IsUpper(prev) || nextIsLower {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+	flush()
+
+	return words
+}
+
+// CamelCase converts the string to camelCase, ie "user_id" -> "userId".
+func CamelCase(s string) string {
+	words := Words(s)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = title(word)
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+// PascalCase converts the string to PascalCase, ie "user_id" -> "UserId".
+func PascalCase(s string) string {
+	words := Words(s)
+	for i, word := range words {
+		words[i] = title(word)
+	}
+
+	return strings.Join(words, "")
+}
+
+// SnakeCase converts the string to snake_case, ie "userID" -> "user_id".
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "_"))
+}
+
+// KebabCase converts the string to kebab-case, ie "userID" -> "user-id".
+func KebabCase(s string) string {
+	return strings.ToLower(strings.Join(Words(s), "-"))
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToUpper(runes[0])
+	}
+
+	return string(runes)
+}
+
+var (
+	irregularPlurals = map[string]string{
+		"person": "people", "child": "children", "man": "men", "woman": "women", "mouse": "mice",
+		"goose": "geese", "foot": "feet", "tooth": "teeth", "ox": "oxen", "leaf": "leaves", "life": "lives",
+		"knife": "knives", "wife": "wives", "half": "halves", "index": "indices", "criterion": "criteria",
+	}
+	irregularSingulars = invert(irregularPlurals)
+	uncountables       = map[string]struct{}{
+		"data": {}, "metadata": {}, "information": {}, "equipment": {}, "news": {}, "series": {},
+		"species": {}, "sheep": {}, "fish": {}, "deer": {}, "money": {}, "rice": {},
+	}
+)
+
+// Pluralize returns the English plural form of the word, ie "category" -> "categories".
+func Pluralize(word string) string {
+	lower := strings.ToLower(word)
+
+	if _, ok := uncountables[lower]; ok || word == "" {
+		return word
+	}
+	if plural, ok := irregularPlurals[lower]; ok {
+		return matchCase(word, plural)
+	}
+	if _, ok := irregularSingulars[lower]; ok {
+		return word
+	}
+
+	switch {
+	case hasAnySuffix(lower, "s", "x", "z", "ch", "sh"):
+		return word + matchCase(word[len(word)-1:], "es")
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !isVowel(lower[len(lower)-2]):
+		return word[:len(word)-1] + matchCase(word[len(word)-1:], "ies")
+	default:
+		return word + matchCase(word[len(word)-1:], "s")
+	}
+}
+
+// Singularize returns the English singular form of the word, ie "categories" -> "category".
+func Singularize(word string) string {
+	lower := strings.ToLower(word)
+
+	if _, ok := uncountables[lower]; ok || word == "" {
+		return word
+	}
+	if singular, ok := irregularSingulars[lower]; ok {
+		return matchCase(word, singular)
+	}
+	if _, ok := irregularPlurals[lower]; ok {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(lower) > 3:
+		return word[:len(word)-3] + matchCase(word[len(word)-1:], "y")
+	case hasAnySuffix(lower, "sses", "shes", "ches", "xes", "zes", "uses"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "ss") || strings.HasSuffix(lower, "us") || strings.HasSuffix(lower, "is"):
+		return word
+	case strings.HasSuffix(lower, "s"):
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// SanitizeIdentifier converts the string to a valid identifier, replacing any characters that aren't letters, digits or underscores
+// with underscores and prefixing it with an underscore if it would otherwise start with a digit.
+func SanitizeIdentifier(s string) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+			b.WriteRune(r)
+		case unicode.IsDigit(r):
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	if b.Len() == 0 {
+		return "_"
+	}
+
+	return b.String()
+}
+
+var (
+	goKeywords = keywords("break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+		"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var")
+	tsKeywords = keywords("break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do", "else",
+		"enum", "export", "extends", "false", "finally", "for", "function", "if", "import", "in", "instanceof", "new", "null",
+		"return", "super", "switch", "this", "throw", "true", "try", "typeof", "var", "void", "while", "with", "as", "implements",
+		"interface", "let", "package", "private", "protected", "public", "static", "yield", "any", "boolean", "constructor",
+		"declare", "get", "module", "require", "number", "set", "string", "symbol", "type", "from", "of", "await", "async")
+	pyKeywords = keywords("False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue", "def",
+		"del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal",
+		"not", "or", "pass", "raise", "return", "try", "while", "with", "yield", "match", "case", "type")
+)
+
+// GoIdentifier sanitizes the string into a valid Go identifier, appending an underscore if it is a Go keyword (ie "type" -> "type_").
+func GoIdentifier(s string) string {
+	return escapeKeyword(SanitizeIdentifier(s), goKeywords)
+}
+
+// TSIdentifier sanitizes the string into a valid TypeScript identifier, appending an underscore if it is a reserved or
+// contextual TypeScript keyword (ie "class" -> "class_").
+func TSIdentifier(s string) string {
+	return escapeKeyword(SanitizeIdentifier(s), tsKeywords)
+}
+
+// PyIdentifier sanitizes the string into a valid Python identifier, appending an underscore if it is a Python keyword
+// or soft keyword (ie "from" -> "from_"), following PEP 8.
+func PyIdentifier(s string) string {
+	return escapeKeyword(SanitizeIdentifier(s), pyKeywords)
+}
+
+func escapeKeyword(s string, kws map[string]struct{}) string {
+	if _, ok := kws[s]; ok {
+		return s + "_"
+	}
+
+	return s
+}
+
+func keywords(kws ...string) map[string]struct{} {
+	m := make(map[string]struct{}, len(kws))
+	for _, kw := range kws {
+		m[kw] = struct{}{}
+	}
+
+	return m
+}
+
+func invert(m map[string]string) map[string]string {
+	inverted := make(map[string]string, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+
+	return inverted
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) != -1
+}
+
+// matchCase returns replacement in upper case if s is entirely upper case, or with its first letter upper cased if s starts with one.
+func matchCase(s, replacement string) string {
+	switch {
+	case s == strings.ToUpper(s) && s != strings.ToLower(s):
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper([]rune(s)[0]):
+		return title(replacement)
+	default:
+		return replacement
+	}
+}
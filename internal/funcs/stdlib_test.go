@@ -0,0 +1,65 @@
+package funcs_test
+
+import (
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate/internal/funcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseConversion(t *testing.T) {
+	tests := []struct {
+		in     string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{in: "user_id", camel: "userId", pascal: "UserId", snake: "user_id", kebab: "user-id"},
+		{in: "HTTPServer", camel: "httpServer", pascal: "HttpServer", snake: "http_server", kebab: "http-server"},
+		{in: "getUserByID", camel: "getUserById", pascal: "GetUserById", snake: "get_user_by_id", kebab: "get-user-by-id"},
+		{in: "some-kebab case", camel: "someKebabCase", pascal: "SomeKebabCase", snake: "some_kebab_case", kebab: "some-kebab-case"},
+		{in: "v2Api", camel: "v2Api", pascal: "V2Api", snake: "v2_api", kebab: "v2-api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.camel, funcs.CamelCase(tt.in))
+			assert.Equal(t, tt.pascal, funcs.PascalCase(tt.in))
+			assert.Equal(t, tt.snake, funcs.SnakeCase(tt.in))
+			assert.Equal(t, tt.kebab, funcs.KebabCase(tt.in))
+		})
+	}
+}
+
+func TestPluralization(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{singular: "user", plural: "users"},
+		{singular: "category", plural: "categories"},
+		{singular: "key", plural: "keys"},
+		{singular: "status", plural: "statuses"},
+		{singular: "box", plural: "boxes"},
+		{singular: "branch", plural: "branches"},
+		{singular: "Person", plural: "People"},
+		{singular: "data", plural: "data"},
+		{singular: "case", plural: "cases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.singular, func(t *testing.T) {
+			assert.Equal(t, tt.plural, funcs.Pluralize(tt.singular))
+			assert.Equal(t, tt.singular, funcs.Singularize(tt.plural))
+		})
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	assert.Equal(t, "_1st_name", funcs.SanitizeIdentifier("1st-name"))
+	assert.Equal(t, "_", funcs.SanitizeIdentifier(""))
+	assert.Equal(t, "type_", funcs.GoIdentifier("type"))
+	assert.Equal(t, "class", funcs.GoIdentifier("class"))
+	assert.Equal(t, "class_", funcs.TSIdentifier("class"))
+	assert.Equal(t, "from_", funcs.PyIdentifier("from"))
+	assert.Equal(t, "x_y", funcs.PyIdentifier("x.y"))
+}
@@ -0,0 +1,157 @@
+package funcs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Whitespace returns functions for managing whitespace and indentation when generating code.
+func Whitespace() map[string]any {
+	return map[string]any{
+		"indent":         Indent,
+		"nindent":        NIndent,
+		"dedent":         Dedent,
+		"trimBlankLines": TrimBlankLines,
+		"wrap":           Wrap,
+		"comment":        Comment,
+		"join":           Join,
+	}
+}
+
+// Indent indents every line of the string by the provided number of spaces.
+func Indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// NIndent is Indent preceded by a newline, for use with {{- so the template's own indentation can be trimmed.
+func NIndent(spaces int, s string) string {
+	return "\n" + Indent(spaces, s)
+}
+
+// Dedent removes the leading whitespace common to every non blank line of the string.
+func Dedent(s string) string {
+	lines := strings.Split(s, "\n")
+
+	var prefix string
+	found := false
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		if !found {
+			prefix = indent
+			found = true
+			continue
+		}
+
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+		if strings.TrimSpace(lines[i]) == "" {
+			lines[i] = ""
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// TrimBlankLines removes any blank lines from the start and end of the string.
+func TrimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+
+	end := len(lines)
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+
+	return strings.Join(lines[start:end], "\n")
+}
+
+// Wrap wraps the string at word boundaries so that no line is longer than width, unless a single word is longer than width.
+// Existing line breaks are preserved.
+func Wrap(width int, s string) string {
+	lines := strings.Split(s, "\n")
+
+	for i, line := range lines {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			lines[i] = ""
+			continue
+		}
+
+		var b strings.Builder
+		lineLen := 0
+
+		for _, word := range words {
+			switch {
+			case lineLen == 0:
+			case lineLen+1+len(word) > width:
+				b.WriteByte('\n')
+				lineLen = 0
+			default:
+				b.WriteByte(' ')
+				lineLen++
+			}
+
+			b.WriteString(word)
+			lineLen += len(word)
+		}
+
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Comment prefixes every line of the string with the provided comment marker (ie "//" or "#") followed by a space.
+// Blank lines are prefixed with the marker alone, so no trailing whitespace is produced.
+func Comment(marker, s string) string {
+	lines := strings.Split(s, "\n")
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = marker
+		} else {
+			lines[i] = marker + " " + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Join joins the elements of the provided slice with the separator. Elements that aren't strings are formatted with fmt.Sprint.
+func Join(sep string, list any) string {
+	if s, ok := list.([]string); ok {
+		return strings.Join(s, sep)
+	}
+
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		if list == nil {
+			return ""
+		}
+		return fmt.Sprint(list)
+	}
+
+	elems := make([]string, v.Len())
+	for i := range elems {
+		elems[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(elems, sep)
+}
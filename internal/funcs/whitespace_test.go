@@ -0,0 +1,20 @@
+package funcs_test
+
+import (
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate/internal/funcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhitespace(t *testing.T) {
+	assert.Equal(t, "  a\n  b", funcs.Indent(2, "a\nb"))
+	assert.Equal(t, "\n  a\n  b", funcs.NIndent(2, "a\nb"))
+	assert.Equal(t, "a\n  b\n\nc", funcs.Dedent("    a\n      b\n  \n    c"))
+	assert.Equal(t, "a\n\nb", funcs.TrimBlankLines("\n  \na\n\nb\n\n"))
+	assert.Equal(t, "the quick\nbrown fox\njumps\n\nover", funcs.Wrap(10, "the quick brown fox jumps\n\nover"))
+	assert.Equal(t, "// a\n//\n// b", funcs.Comment("//", "a\n\nb"))
+	assert.Equal(t, "a, b", funcs.Join(", ", []string{"a", "b"}))
+	assert.Equal(t, "1-true", funcs.Join("-", []any{1, true}))
+	assert.Equal(t, "", funcs.Join(", ", nil))
+}
@@ -0,0 +1,222 @@
+package lsp
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/speakeasy-api/easytemplate/internal/template"
+)
+
+const (
+	// The default markers of sjs blocks and template actions, changes made by a template's pragma aren't taken into account
+	sjsOpen     = "```sjs"
+	sjsClose    = "sjs```"
+	actionOpen  = "{{"
+	actionClose = "}}"
+)
+
+// refRegex matches calls referencing another file by a literal path, in both templates and scripts.
+var refRegex = regexp.MustCompile(`\b(templateFile|templateString|templateDir|require|extends)\b\s*\(?\s*["'\x60]([^"'\x60]*)["'\x60]`)
+
+type codeContext int
+
+const (
+	contextNone codeContext = iota
+	contextTemplate
+	contextScript
+)
+
+// contextAt returns whether the offset within the document is within template code, script code or neither.
+func contextAt(name, text string, offset int) codeContext {
+	if isScript(name) {
+		return contextScript
+	}
+
+	before := text[:offset]
+
+	if open := strings.LastIndex(before, sjsOpen); open >= 0 && !strings.Contains(before[open+len(sjsOpen):], sjsClose) {
+		return contextScript
+	}
+
+	if open := strings.LastIndex(before, actionOpen); open >= 0 && !strings.Contains(before[open:], actionClose) {
+		return contextTemplate
+	}
+
+	return contextNone
+}
+
+// completion returns the template functions or JS globals that could complete the identifier at the position.
+func (s *Server) completion(uri string, pos Position) []CompletionItem {
+	items := []CompletionItem{}
+
+	text, ok := s.docs[uri]
+	if !ok {
+		return items
+	}
+
+	offset := offsetOf(text, pos)
+
+	start := offset
+	for start > 0 && isIdentByte(text[start-1]) {
+		start--
+	}
+	// Fields and methods (ie .Local or context.Local) aren't completed
+	if start > 0 && text[start-1] == '.' {
+		return items
+	}
+	prefix := text[start:offset]
+
+	add := func(name string, kind int, detail string) {
+		if strings.HasPrefix(name, prefix) {
+			items = append(items, CompletionItem{Label: name, Kind: kind, Detail: detail})
+		}
+	}
+
+	e := s.getEngine()
+
+	switch contextAt(s.docName(uri), text, offset) {
+	case contextTemplate:
+		for _, name := range e.TemplateFuncNames() {
+			add(name, kindFunction, "template function")
+		}
+		for _, name := range template.DeclaredTemplateFuncs(text) {
+			add(name, kindFunction, "template function")
+		}
+		for _, name := range template.BuiltinFuncNames() {
+			add(name, kindFunction, "text/template function")
+		}
+	case contextScript:
+		for _, name := range e.JSGlobalNames() {
+			kind := kindFunction
+			if e.IsJSVariable(name) {
+				kind = kindVariable
+			}
+			add(name, kind, "easytemplate global")
+		}
+	case contextNone:
+	}
+
+	return items
+}
+
+// definition returns the location of the file referenced by a templateFile, templateString, require or similar call with a literal path
+// at the position, or nil if there isn't one or it can't be found.
+func (s *Server) definition(uri string, pos Position) *Location {
+	text, ok := s.docs[uri]
+	if !ok {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	if pos.Line >= len(lines) {
+		return nil
+	}
+	line := lines[pos.Line]
+	col := byteOffset(line, pos.Character)
+
+	for _, match := range refRegex.FindAllStringSubmatchIndex(line, -1) {
+		// Include the quotes surrounding the path
+		if col < match[4]-1 || col > match[5]+1 {
+			continue
+		}
+
+		fn := line[match[2]:match[3]]
+		ref := line[match[4]:match[5]]
+
+		filePath, ok := s.locate(fn, ref, s.docName(uri))
+		if !ok {
+			return nil
+		}
+
+		return &Location{URI: s.fileURI(filePath)}
+	}
+
+	return nil
+}
+
+// locate resolves the reference made by the call from the document like the engine does when rendering.
+func (s *Server) locate(fn, ref, from string) (string, bool) {
+	e := s.getEngine()
+
+	if filePath, err := e.Locate(ref, from); err == nil {
+		return filePath, true
+	}
+
+	if fn == "require" {
+		// require falls back to resolving relative paths through the search locations, and bare paths relative to the calling script
+		fallback := path.Join(path.Dir(from), ref)
+		if strings.HasPrefix(ref, ".") {
+			fallback = ref
+		}
+
+		if filePath, err := e.Locate(fallback, ""); err == nil {
+			return filePath, true
+		}
+	}
+
+	return "", false
+}
+
+func isScript(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".js" || ext == ".ts"
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// offsetOf returns the byte offset within the text of the position.
+func offsetOf(text string, pos Position) int {
+	offset := 0
+	for i := 0; i < pos.Line; i++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+
+	line := text[offset:]
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+
+	return offset + byteOffset(line, pos.Character)
+}
+
+// byteOffset returns the byte offset within the line of the UTF-16 character offset used by the protocol.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += runeLen16(r)
+	}
+
+	return len(line)
+}
+
+// utf16Len returns the length of the string in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		n += runeLen16(r)
+		s = s[size:]
+	}
+
+	return n
+}
+
+// runeLen16 returns the number of UTF-16 code units needed to encode the rune.
+func runeLen16(r rune) int {
+	if r >= 0x10000 { //nolint:mnd
+		return 2 //nolint:mnd
+	}
+
+	return 1
+}
@@ -0,0 +1,262 @@
+// Package lsp implements a language server for easytemplate templates and scripts, providing diagnostics, completion
+// and go-to-definition over the language server protocol.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/speakeasy-api/easytemplate/internal/tooling"
+)
+
+const (
+	serverName       = "easytemplate"
+	diagnosticSource = "easytemplate"
+)
+
+// Server is a language server for easytemplate templates and scripts. It isn't safe for concurrent use, messages are handled in order.
+type Server struct {
+	newEngine func(root string) *easytemplate.Engine
+
+	engine *easytemplate.Engine
+	root   string
+	// docs holds the contents of each open document by URI
+	docs map[string]string
+	w    io.Writer
+}
+
+// NewServer creates a new Server, using newEngine to create the engine used to analyze documents. newEngine is called with the
+// root directory of the workspace once it's known, and the engine it returns should read files relative to it.
+func NewServer(newEngine func(root string) *easytemplate.Engine) *Server {
+	return &Server{
+		newEngine: newEngine,
+		docs:      map[string]string{},
+	}
+}
+
+// Serve reads messages from r and writes responses and notifications to w (ie stdin and stdout) until the client sends exit
+// or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+
+	for {
+		msg, err := readMessage(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			var rErr *responseError
+			if errors.As(err, &rErr) {
+				if err := writeMessage(w, &message{ID: nullID(), Error: rErr}); err != nil {
+					return err
+				}
+				continue
+			}
+
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(msg)
+
+		// Notifications aren't responded to
+		if msg.ID == nil {
+			continue
+		}
+
+		resp := &message{ID: msg.ID}
+		if err != nil {
+			rErr := &responseError{Code: codeInternalError, Message: err.Error()}
+			errors.As(err, &rErr)
+			resp.Error = rErr
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+
+		if err := writeMessage(w, resp); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		var params initializeParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params), nil
+	case "initialized", "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// Documents are synced in full, so the last change holds the current contents
+		return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return completionList{Items: s.completion(params.TextDocument.URI, params.Position)}, nil
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params.TextDocument.URI, params.Position), nil
+	default:
+		if msg.ID == nil {
+			// Unknown notifications (ie $/cancelRequest) can be ignored
+			return nil, nil
+		}
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %s not found", msg.Method)}
+	}
+}
+
+func (s *Server) initialize(params initializeParams) initializeResult {
+	root := params.RootPath
+	if params.RootURI != "" {
+		root = uriToPath(params.RootURI)
+	}
+	s.setRoot(root)
+
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:   textDocumentSyncFull,
+			CompletionProvider: &completionOptions{},
+			DefinitionProvider: true,
+		},
+		ServerInfo: serverInfo{Name: serverName},
+	}
+}
+
+func (s *Server) setRoot(root string) {
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+
+	s.root = root
+	s.engine = s.newEngine(root)
+}
+
+// getEngine returns the tooling view of the engine, creating one for the working directory if the client hasn't initialized the server.
+func (s *Server) getEngine() tooling.Engine {
+	if s.engine == nil {
+		s.setRoot("")
+	}
+
+	return tooling.Of(s.engine)
+}
+
+// update stores the document's contents and publishes its diagnostics.
+func (s *Server) update(uri, text string) error {
+	s.docs[uri] = text
+
+	diagnostics := []Diagnostic{}
+
+	lines := strings.Split(text, "\n")
+
+	for _, issue := range s.getEngine().LintSource(s.docName(uri), text) {
+		line := 0
+		if issue.Line > 0 && issue.Line <= len(lines) {
+			line = issue.Line - 1
+		}
+
+		diagnostics = append(diagnostics, Diagnostic{
+			Range: Range{
+				Start: Position{Line: line},
+				End:   Position{Line: line, Character: utf16Len(strings.TrimSuffix(lines[line], "\r"))},
+			},
+			Severity: severityError,
+			Source:   diagnosticSource,
+			Message:  issue.Message,
+		})
+	}
+
+	return s.publishDiagnostics(uri, diagnostics)
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	params, err := json.Marshal(publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	if err != nil {
+		return err
+	}
+
+	return writeMessage(s.w, &message{Method: "textDocument/publishDiagnostics", Params: params})
+}
+
+// docName returns the name the engine knows the document by, its slash separated path relative to the root if it's within it.
+func (s *Server) docName(uri string) string {
+	p := uriToPath(uri)
+
+	if rel, err := filepath.Rel(s.root, p); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+
+	return filepath.ToSlash(p)
+}
+
+// fileURI returns the URI of the file the engine read from the path.
+func (s *Server) fileURI(name string) string {
+	p := filepath.FromSlash(name)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(s.root, p)
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String()
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return filepath.FromSlash(u.Path)
+}
+
+func unmarshalParams(msg *message, v any) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func nullID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
@@ -0,0 +1,304 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/speakeasy-api/easytemplate/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcMessage struct {
+	ID     *int            `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// client talks to the server over a pair of pipes, as an editor would over stdio.
+type client struct {
+	t      *testing.T
+	w      io.Writer
+	r      *bufio.Reader
+	nextID int
+}
+
+func (c *client) send(id *int, method string, params any) {
+	c.t.Helper()
+
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = *id
+	}
+
+	body, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	require.NoError(c.t, err)
+}
+
+func (c *client) read() rpcMessage {
+	c.t.Helper()
+
+	headers, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	require.NoError(c.t, err)
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	require.NoError(c.t, err)
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(c.r, body)
+	require.NoError(c.t, err)
+
+	var msg rpcMessage
+	require.NoError(c.t, json.Unmarshal(body, &msg))
+
+	return msg
+}
+
+func (c *client) request(method string, params any) rpcMessage {
+	c.t.Helper()
+
+	c.nextID++
+	id := c.nextID
+	c.send(&id, method, params)
+
+	msg := c.read()
+	require.NotNil(c.t, msg.ID)
+	require.Equal(c.t, id, *msg.ID)
+
+	return msg
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.send(nil, method, params)
+}
+
+func (c *client) open(uri, text string) []lsp.Diagnostic {
+	c.t.Helper()
+
+	c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "stmpl", "version": 1, "text": text}})
+
+	msg := c.read()
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+
+	var params struct {
+		URI         string           `json:"uri"`
+		Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+	}
+	require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+	require.Equal(c.t, uri, params.URI)
+
+	return params.Diagnostics
+}
+
+func position(uri string, line, character int) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": map[string]any{"line": line, "character": character}}
+}
+
+func fileURI(p string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String()
+}
+
+func startServer(t *testing.T, root string) *client {
+	t.Helper()
+
+	server := lsp.NewServer(func(root string) *easytemplate.Engine {
+		return easytemplate.New(
+			easytemplate.WithReadFileSystem(os.DirFS(root)),
+			easytemplate.WithSearchLocations([]string{"templates"}),
+			easytemplate.WithFileAccess(),
+			easytemplate.WithJSFuncs(map[string]func(call easytemplate.CallContext) goja.Value{
+				"myFunc": func(call easytemplate.CallContext) goja.Value { return goja.Undefined() },
+			}),
+		)
+	})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(inR, outW)
+		outW.Close()
+	}()
+
+	c := &client{t: t, w: inW, r: bufio.NewReader(outR)}
+
+	t.Cleanup(func() {
+		c.request("shutdown", nil)
+		c.notify("exit", nil)
+		require.NoError(t, <-done)
+	})
+
+	res := c.request("initialize", map[string]any{"rootUri": fileURI(root)})
+	require.Nil(t, res.Error)
+
+	var result struct {
+		Capabilities struct {
+			DefinitionProvider bool `json:"definitionProvider"`
+		} `json:"capabilities"`
+	}
+	require.NoError(t, json.Unmarshal(res.Result, &result))
+	assert.True(t, result.Capabilities.DefinitionProvider)
+
+	c.notify("initialized", map[string]any{})
+
+	return c
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	}
+
+	return root
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	root := writeFiles(t, map[string]string{})
+	c := startServer(t, root)
+
+	uri := fileURI(filepath.Join(root, "templates", "main.stmpl"))
+
+	diagnostics := c.open(uri, "hello\n{{ if .Local }}\n")
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, 2, diagnostics[0].Range.Start.Line)
+	assert.Contains(t, diagnostics[0].Message, "unexpected EOF")
+
+	diagnostics = c.open(uri, "{{ unknownFunc }}\n{{ templateString \"missing.stmpl\" . }}")
+	require.Len(t, diagnostics, 2)
+	assert.Contains(t, diagnostics[0].Message, `function "unknownFunc" not defined`)
+	assert.Equal(t, 1, diagnostics[1].Range.Start.Line)
+	assert.Contains(t, diagnostics[1].Message, "missing.stmpl")
+
+	// Fixing the document clears its diagnostics
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "hello {{ .Local.name }}"}},
+	})
+	msg := c.read()
+	require.Equal(t, "textDocument/publishDiagnostics", msg.Method)
+	assert.JSONEq(t, fmt.Sprintf(`{"uri": %q, "diagnostics": []}`, uri), string(msg.Params))
+}
+
+func TestServer_Completion(t *testing.T) {
+	root := writeFiles(t, map[string]string{})
+	c := startServer(t, root)
+
+	uri := fileURI(filepath.Join(root, "templates", "main.stmpl"))
+	text := "{{ templ }}\n```sjs\nre\nsjs```\nplain te\n{{ .Loc }}"
+	c.open(uri, text)
+
+	items := func(line, character int) []lsp.CompletionItem {
+		res := c.request("textDocument/completion", position(uri, line, character))
+		require.Nil(t, res.Error)
+
+		var list struct {
+			Items []lsp.CompletionItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(res.Result, &list))
+
+		return list.Items
+	}
+	labels := func(line, character int) []string {
+		names := []string{}
+		for _, item := range items(line, character) {
+			names = append(names, item.Label)
+		}
+
+		return names
+	}
+
+	templateLabels := labels(0, 8)
+	assert.Contains(t, templateLabels, "templateFile")
+	assert.Contains(t, templateLabels, "templateString")
+	assert.NotContains(t, templateLabels, "printf")
+
+	jsLabels := labels(2, 2)
+	assert.Contains(t, jsLabels, "render")
+	assert.Contains(t, jsLabels, "require")
+	assert.Contains(t, jsLabels, "registerTemplateFunc")
+	assert.NotContains(t, jsLabels, "templateFile")
+
+	assert.Contains(t, labels(2, 0), "myFunc")
+	assert.Contains(t, labels(2, 0), "context")
+
+	kinds := map[string]int{}
+	for _, item := range items(2, 0) {
+		kinds[item.Label] = item.Kind
+	}
+	assert.Equal(t, kinds["fs"], kinds["context"])
+	assert.NotEqual(t, kinds["myFunc"], kinds["context"])
+	assert.Equal(t, kinds["myFunc"], kinds["readJSON"])
+	assert.Empty(t, labels(4, 8))
+	assert.Empty(t, labels(5, 7))
+}
+
+func TestServer_Definition(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"templates/partial.stmpl":     "partial",
+		"templates/models/user.stmpl": "user",
+		"scripts/helpers.js":          "function helper() {}",
+	})
+	c := startServer(t, root)
+
+	tmplURI := fileURI(filepath.Join(root, "templates", "main.stmpl"))
+	c.open(tmplURI, "{{ templateString \"./partial.stmpl\" . }}\n{{ templateString \"models/user.stmpl\" . }}\n{{ templateString \"missing.stmpl\" . }}")
+
+	scriptURI := fileURI(filepath.Join(root, "scripts", "main.js"))
+	c.open(scriptURI, "require(\"helpers.js\");\ntemplateFile(\"partial.stmpl\", \"out.txt\", {});")
+
+	definition := func(uri string, line, character int) string {
+		res := c.request("textDocument/definition", position(uri, line, character))
+		require.Nil(t, res.Error)
+
+		if string(res.Result) == "null" {
+			return ""
+		}
+
+		var loc lsp.Location
+		require.NoError(t, json.Unmarshal(res.Result, &loc))
+
+		return loc.URI
+	}
+
+	assert.Equal(t, fileURI(filepath.Join(root, "templates", "partial.stmpl")), definition(tmplURI, 0, 22))
+	assert.Equal(t, fileURI(filepath.Join(root, "templates", "models", "user.stmpl")), definition(tmplURI, 1, 20))
+	assert.Empty(t, definition(tmplURI, 2, 20))
+	// Outside of a path
+	assert.Empty(t, definition(tmplURI, 0, 2))
+
+	assert.Equal(t, fileURI(filepath.Join(root, "scripts", "helpers.js")), definition(scriptURI, 0, 10))
+	assert.Equal(t, fileURI(filepath.Join(root, "templates", "partial.stmpl")), definition(scriptURI, 1, 16))
+}
+
+func TestServer_UnknownMethod(t *testing.T) {
+	c := startServer(t, t.TempDir())
+
+	res := c.request("textDocument/hover", position("file:///main.stmpl", 0, 0))
+	require.NotNil(t, res.Error)
+	assert.Equal(t, -32601, res.Error.Code)
+}
@@ -0,0 +1,235 @@
+// Package schema provides validation of template data against a subset of JSON Schema.
+//
+// The supported keywords are type, properties, required, additionalProperties, items and enum, along with the annotations
+// $schema, $id, $comment, title, description, default and examples which don't affect validation. Schemas using any other
+// keyword fail to parse, rather than silently accepting data the keyword would reject.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrValidation is returned when data doesn't match a schema.
+	ErrValidation = errors.New("schema validation failed")
+	// ErrUnsupportedKeyword is returned when parsing a schema using a keyword that isn't supported.
+	ErrUnsupportedKeyword = errors.New("unsupported schema keyword")
+)
+
+// supportedKeywords are the keywords that are validated or, as annotations, can safely be ignored.
+var supportedKeywords = map[string]struct{}{
+	"type": {}, "properties": {}, "required": {}, "additionalProperties": {}, "items": {}, "enum": {},
+	"$schema": {}, "$id": {}, "$comment": {}, "title": {}, "description": {}, "default": {}, "examples": {},
+}
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	Type                 types              `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *additional        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+}
+
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(b, &keywords); err != nil {
+		return err
+	}
+
+	unsupported := []string{}
+	for keyword := range keywords {
+		if _, ok := supportedKeywords[keyword]; !ok {
+			unsupported = append(unsupported, keyword)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("%w: %s", ErrUnsupportedKeyword, strings.Join(unsupported, ", "))
+	}
+
+	// The alias doesn't have this method, so is unmarshalled as a plain struct
+	type plain Schema
+
+	return json.Unmarshal(b, (*plain)(s))
+}
+
+type types []string
+
+func (t *types) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*t = types{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return fmt.Errorf("type must be a string or array of strings: %w", err)
+	}
+	*t = multiple
+
+	return nil
+}
+
+type additional struct {
+	allowed bool
+	schema  *Schema
+}
+
+func (a *additional) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &a.allowed); err == nil {
+		return nil
+	}
+
+	a.allowed = true
+	a.schema = &Schema{}
+
+	return json.Unmarshal(b, a.schema)
+}
+
+// Parse parses the provided JSON Schema document.
+func Parse(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	return s, nil
+}
+
+// Validate validates data against the schema, root is used as the prefix of the path reported in any error (for example .Local).
+func (s *Schema) Validate(root string, data any) error {
+	normalized, err := normalize(data)
+	if err != nil {
+		return err
+	}
+
+	return s.validate(root, normalized)
+}
+
+// normalize converts the data to the generic types produced by encoding/json so Go structs and values exported from js are treated the same.
+func normalize(data any) (any, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert data for validation: %w", err)
+	}
+
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("failed to convert data for validation: %w", err)
+	}
+
+	return out, nil
+}
+
+//nolint:cyclop
+func (s *Schema) validate(path string, data any) error {
+	if len(s.Type) > 0 && !s.matchesType(data) {
+		return fmt.Errorf("%w: %s: expected %s, got %s", ErrValidation, path, strings.Join(s.Type, " or "), typeOf(data))
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, v := range s.Enum {
+			if reflect.DeepEqual(v, data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s: value %v is not one of the allowed values", ErrValidation, path, data)
+		}
+	}
+
+	switch d := data.(type) {
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := d[req]; !ok {
+				return fmt.Errorf("%w: %s.%s: required property is missing", ErrValidation, path, req)
+			}
+		}
+
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			propPath := path + "." + k
+
+			if prop, ok := s.Properties[k]; ok {
+				if err := prop.validate(propPath, d[k]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if !s.AdditionalProperties.allowed {
+				return fmt.Errorf("%w: %s: property is not allowed", ErrValidation, propPath)
+			}
+			if s.AdditionalProperties.schema != nil {
+				if err := s.AdditionalProperties.schema.validate(propPath, d[k]); err != nil {
+					return err
+				}
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range d {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) matchesType(data any) bool {
+	actual := typeOf(data)
+
+	for _, t := range s.Type {
+		if t == actual {
+			return true
+		}
+		if t == "number" && actual == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func typeOf(data any) string {
+	switch d := data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if d == float64(int64(d)) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", data)
+	}
+}
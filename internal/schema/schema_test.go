@@ -0,0 +1,125 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate/internal/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := schema.Parse([]byte(`{
+		"type": "object",
+		"required": ["name"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string"},
+			"count": {"type": "integer"},
+			"kind": {"enum": ["a", "b"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	type data struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	tests := []struct {
+		name    string
+		data    any
+		wantErr string
+	}{
+		{
+			name: "valid map",
+			data: map[string]any{"name": "test", "count": int64(1), "kind": "a", "tags": []any{"x"}},
+		},
+		{
+			name: "valid struct",
+			data: data{Name: "test", Count: 2},
+		},
+		{
+			name:    "missing required property",
+			data:    map[string]any{"count": 1},
+			wantErr: "schema validation failed: .Local.name: required property is missing",
+		},
+		{
+			name:    "additional property",
+			data:    map[string]any{"name": "test", "nmae": "test"},
+			wantErr: "schema validation failed: .Local.nmae: property is not allowed",
+		},
+		{
+			name:    "wrong type",
+			data:    map[string]any{"name": "test", "count": 1.5},
+			wantErr: "schema validation failed: .Local.count: expected integer, got number",
+		},
+		{
+			name:    "invalid enum",
+			data:    map[string]any{"name": "test", "kind": "c"},
+			wantErr: "schema validation failed: .Local.kind: value c is not one of the allowed values",
+		},
+		{
+			name:    "invalid array item",
+			data:    map[string]any{"name": "test", "tags": []any{"x", 1}},
+			wantErr: "schema validation failed: .Local.tags[1]: expected string, got integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(".Local", tt.data)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, schema.ErrValidation)
+			}
+		})
+	}
+}
+
+func TestParse_UnsupportedKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:   "annotations",
+			schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "client", "title": "Client", "description": "A client", "properties": {"name": {"type": "string", "default": "a", "examples": ["b"], "$comment": "c"}}}`,
+		},
+		{
+			name:    "root keyword",
+			schema:  `{"type": "object", "oneOf": [{"required": ["a"]}, {"required": ["b"]}]}`,
+			wantErr: "unsupported schema keyword: oneOf",
+		},
+		{
+			name:    "property keyword",
+			schema:  `{"properties": {"name": {"type": "string", "pattern": "^[a-z]+$", "minLength": 1}}}`,
+			wantErr: "unsupported schema keyword: minLength, pattern",
+		},
+		{
+			name:    "items keyword",
+			schema:  `{"items": {"$ref": "#/definitions/item"}}`,
+			wantErr: "unsupported schema keyword: $ref",
+		},
+		{
+			name:    "additional properties keyword",
+			schema:  `{"additionalProperties": {"type": "number", "minimum": 0}}`,
+			wantErr: "unsupported schema keyword: minimum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := schema.Parse([]byte(tt.schema))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, schema.ErrUnsupportedKeyword)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"path"
+	"strings"
+)
+
+// Formatter formats the rendered output of a template before it is written.
+type Formatter interface {
+	// Format returns the formatted contents of the file at path.
+	Format(path string, src []byte) ([]byte, error)
+}
+
+// FormatError is returned when a Formatter fails to format the output of a template.
+type FormatError struct {
+	// TemplateFile is the template that rendered the output.
+	TemplateFile string
+	// OutFile is the file the output was to be written to.
+	OutFile string
+	// OutputLine is the line of the rendered output (not of the template) the formatter reported the error on, or 0 if unknown.
+	OutputLine int
+	// Source is the text of the output line the error was reported on, to help find the template action that produced it.
+	Source string
+	// Err is the error returned by the formatter.
+	Err error
+}
+
+func (e *FormatError) Error() string {
+	if e.OutputLine > 0 {
+		return fmt.Sprintf("failed to format %s (rendered from template %s) at output line %d %q: %s", e.OutFile, e.TemplateFile, e.OutputLine, e.Source, e.Err.Error())
+	}
+
+	return fmt.Sprintf("failed to format %s (rendered from template %s): %s", e.OutFile, e.TemplateFile, e.Err.Error())
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+func (t *Templator) format(templateFile, outFile string, out []byte) ([]byte, error) {
+	f, ok := t.Formatters[strings.ToLower(path.Ext(outFile))]
+	if !ok {
+		return out, nil
+	}
+
+	formatted, err := f.Format(outFile, out)
+	if err != nil {
+		line := formatErrorLine(err, out)
+
+		return nil, &FormatError{
+			TemplateFile: templateFile,
+			OutFile:      outFile,
+			OutputLine:   line,
+			Source:       outputLine(out, line),
+			Err:          err,
+		}
+	}
+
+	return formatted, nil
+}
+
+// formatErrorLine returns the line of the output the error occurred on, for the errors returned by the built-in formatters.
+func formatErrorLine(err error, out []byte) int {
+	var scanErrs scanner.ErrorList
+	if errors.As(err, &scanErrs) && len(scanErrs) > 0 {
+		return scanErrs[0].Pos.Line
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && int(syntaxErr.Offset) <= len(out) {
+		return strings.Count(string(out[:syntaxErr.Offset]), "\n") + 1
+	}
+
+	return 0
+}
+
+// outputLine returns the text of the 1-based line of the output, or an empty string if it doesn't exist.
+func outputLine(out []byte, line int) string {
+	lines := strings.Split(string(out), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(lines[line-1])
+}
@@ -0,0 +1,182 @@
+package template
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Meta is the metadata declared in a template's front-matter block.
+type Meta struct {
+	// Output is the default output file for the template, used by templateFile when no outFile is provided. It may contain template actions.
+	Output string `yaml:"output" toml:"output"`
+	// Description is a human readable description of the template.
+	Description string `yaml:"description" toml:"description"`
+	// Required lists the keys that must be present in the template's Local data.
+	Required []string `yaml:"required" toml:"required"`
+	// Recurse is the number of times to recurse the template, equivalent to {{ recurse n }} on the first line.
+	Recurse int `yaml:"recurse" toml:"recurse"`
+	// Local is default Local data for the template, merged beneath any data passed to the template.
+	Local map[string]any `yaml:"local" toml:"local"`
+	// Enabled is either a boolean or a template expression (ie "{{ .Local.enabled }}") deciding whether the template is rendered at all.
+	Enabled any `yaml:"enabled" toml:"enabled"`
+}
+
+const (
+	yamlFrontMatterDelim = "---"
+	tomlFrontMatterDelim = "+++"
+)
+
+// splitFrontMatter separates a front-matter block from the start of the input, returning the parsed metadata,
+// the remaining template and the number of lines the front-matter block occupied.
+// A nil Meta is returned if the input doesn't start with a front-matter block.
+func splitFrontMatter(input string) (*Meta, string, int, error) {
+	normalized := strings.ReplaceAll(input, "\r\n", "\n")
+
+	var delim string
+	switch {
+	case strings.HasPrefix(normalized, yamlFrontMatterDelim+"\n"):
+		delim = yamlFrontMatterDelim
+	case strings.HasPrefix(normalized, tomlFrontMatterDelim+"\n"):
+		delim = tomlFrontMatterDelim
+	default:
+		return nil, input, 0, nil
+	}
+
+	rest := normalized[len(delim)+1:]
+
+	var block string
+	switch {
+	case strings.HasPrefix(rest, delim+"\n") || rest == delim:
+		block = ""
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, delim), "\n")
+	default:
+		end := strings.Index(rest, "\n"+delim+"\n")
+		if end == -1 {
+			if !strings.HasSuffix(rest, "\n"+delim) {
+				return nil, "", 0, fmt.Errorf("front-matter block starting with %s is not terminated", delim)
+			}
+			end = len(rest) - len(delim) - 1
+		}
+
+		block = rest[:end+1]
+		rest = strings.TrimPrefix(rest[end+1+len(delim):], "\n")
+	}
+
+	meta := &Meta{}
+
+	var err error
+	if delim == yamlFrontMatterDelim {
+		err = yaml.Unmarshal([]byte(block), meta)
+	} else {
+		_, err = toml.Decode(block, meta)
+	}
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to parse front-matter: %w", err)
+	}
+
+	lines := strings.Count(normalized, "\n") - strings.Count(rest, "\n")
+
+	return meta, rest, lines, nil
+}
+
+// applyDefaults merges the default Local data from the front-matter beneath the provided data.
+func (m *Meta) applyDefaults(data any) any {
+	if m == nil || len(m.Local) == 0 {
+		return data
+	}
+
+	if data == nil {
+		return m.Local
+	}
+
+	d, ok := data.(map[string]any)
+	if !ok {
+		return data
+	}
+
+	merged := make(map[string]any, len(m.Local)+len(d))
+	for k, v := range m.Local {
+		merged[k] = v
+	}
+	for k, v := range d {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+func (m *Meta) checkRequired(name string, data any) error {
+	if m == nil {
+		return nil
+	}
+
+	for _, key := range m.Required {
+		if !hasKey(data, key) {
+			return fmt.Errorf("template %s requires data key %s", name, key)
+		}
+	}
+
+	return nil
+}
+
+func hasKey(data any, key string) bool {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return false
+		}
+		return v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())).IsValid()
+	case reflect.Struct:
+		return v.FieldByName(key).IsValid()
+	default:
+		return false
+	}
+}
+
+func (t *Templator) isEnabled(syn *syntax, name string, m *Meta, data any) (bool, error) {
+	if m == nil || m.Enabled == nil {
+		return true, nil
+	}
+
+	switch enabled := m.Enabled.(type) {
+	case bool:
+		return enabled, nil
+	case string:
+		out, err := t.execTemplate(syn, name+":enabled", enabled, data, 0)
+		if err != nil {
+			return false, fmt.Errorf("failed to evaluate enabled condition: %w", err)
+		}
+
+		return isTruthy(out), nil
+	default:
+		return false, fmt.Errorf("enabled must be a boolean or a string, got %T", m.Enabled)
+	}
+}
+
+func isTruthy(s string) bool {
+	s = strings.TrimSpace(s)
+
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+
+	switch s {
+	case "", "0", "<no value>", "no":
+		return false
+	default:
+		return true
+	}
+}
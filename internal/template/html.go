@@ -0,0 +1,71 @@
+package template
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+)
+
+// execHTMLTemplateTo executes the template into w with html/template, contextually escaping its output.
+// html/template doesn't allow templates to be added to a namespace once any of it has executed,
+// so partials and layouts are parsed into a fresh namespace for each execution.
+func (t *Templator) execHTMLTemplateTo(w io.Writer, syn *syntax, name string, tmplContent string, data any, replacedLines int) error {
+	def := t.defaultSyntax()
+
+	root := htmltemplate.New("").Delims(def.leftDelim, def.rightDelim).Funcs(t.TmplFuncs)
+	if t.Strict {
+		root.Option("missingkey=error")
+	}
+
+	names := make([]string, 0, len(t.Partials))
+	for partial := range t.Partials {
+		names = append(names, partial)
+	}
+	sort.Strings(names)
+
+	for _, partial := range names {
+		if _, err := root.New(partial).Parse(t.Partials[partial]); err != nil {
+			return fmt.Errorf("failed to parse partial %s: %w", partial, err)
+		}
+	}
+
+	layouts, err := t.readLayouts(syn, name, tmplContent)
+	if err != nil {
+		return err
+	}
+
+	// Parse from the outermost layout in, so each template's blocks override those of the layout it extends
+	var outer *htmltemplate.Template
+	for i := len(layouts) - 1; i >= 0; i-- {
+		tmp, err := root.New(layouts[i].name).Delims(layouts[i].syn.leftDelim, layouts[i].syn.rightDelim).Parse(layouts[i].content)
+		if err != nil {
+			return fmt.Errorf("failed to parse layout: %w", err)
+		}
+
+		if outer == nil {
+			outer = tmp
+		}
+	}
+
+	tmp, err := root.New(name).Delims(syn.leftDelim, syn.rightDelim).Parse(tmplContent)
+	if err != nil {
+		if t.Debug {
+			//nolint:forbidigo
+			fmt.Println(tmplContent)
+		}
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	if outer != nil {
+		// The template's blocks have been parsed over the layout's, so the layout is what is executed
+		tmp = outer
+	}
+
+	if err := tmp.Execute(w, data); err != nil {
+		err = adjustLineNumber(name, err, replacedLines)
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
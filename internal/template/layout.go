@@ -0,0 +1,111 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+)
+
+const maxLayoutDepth = 10
+
+// parsePartials parses the partials into the base template, so they are available to all templates created from it.
+// Partials are parsed lazily as they may use template functions registered after the engine is initialized.
+func (t *Templator) parsePartials() error {
+	if t.partialsParsed {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.Partials))
+	for name := range t.Partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := t.baseTemplate.New(name).Parse(t.Partials[name]); err != nil {
+			return fmt.Errorf("failed to parse partial %s: %w", name, err)
+		}
+	}
+
+	t.partialsParsed = true
+
+	return nil
+}
+
+type layout struct {
+	name    string
+	content string
+	syn     *syntax
+}
+
+// readLayouts reads the chain of layouts the template extends with {{ extends "layout" }}, innermost first.
+// Each layout is resolved relative to the template extending it, and uses the delimiters set by its own first line pragma.
+func (t *Templator) readLayouts(syn *syntax, name, tmplContent string) ([]layout, error) {
+	layouts := []layout{}
+	content := tmplContent
+	from := name
+
+	for {
+		matches := syn.extendsRegex.FindStringSubmatch(content)
+		if matches == nil {
+			break
+		}
+
+		if len(layouts) == maxLayoutDepth {
+			return nil, fmt.Errorf("layouts nested more than %d deep, check for a cycle in extends", maxLayoutDepth)
+		}
+
+		layoutPath := t.resolveRef(matches[1], from)
+
+		data, err := t.ReadFunc(layoutPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layout %s: %w", matches[1], err)
+		}
+
+		syn, content, _, err = t.syntaxFor(string(data), "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layout %s: %w", matches[1], err)
+		}
+
+		from = layoutPath
+		layouts = append(layouts, layout{name: layoutPath, content: content, syn: syn})
+	}
+
+	return layouts, nil
+}
+
+// parseLayouts resolves the chain of layouts the template extends with {{ extends "layout" }}. A copy of the base template
+// with any layouts parsed into it is returned, along with the outermost layout that should be executed if there are any.
+// The base template is only copied if the template extends a layout or defines blocks, otherwise it is returned as is.
+// Layouts are parsed as plain templates, without evaluating sjs blocks or front-matter.
+func (t *Templator) parseLayouts(syn *syntax, name, tmplContent string) (*template.Template, *template.Template, error) {
+	layouts, err := t.readLayouts(syn, name, tmplContent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(layouts) == 0 && !syn.definesRegex.MatchString(tmplContent) {
+		return t.baseTemplate, nil, nil
+	}
+
+	// Clone so the blocks defined by this template (and its layouts) don't leak into other templates, or override partials
+	base, err := t.baseTemplate.Clone()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to clone base template: %w", err)
+	}
+
+	// Parse from the outermost layout in, so each template's blocks override those of the layout it extends
+	var outer *template.Template
+	for i := len(layouts) - 1; i >= 0; i-- {
+		tmp, err := base.New(layouts[i].name).Delims(layouts[i].syn.leftDelim, layouts[i].syn.rightDelim).Parse(layouts[i].content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse layout: %w", err)
+		}
+
+		if outer == nil {
+			outer = tmp
+		}
+	}
+
+	return base, outer, nil
+}
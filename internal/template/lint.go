@@ -0,0 +1,277 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/speakeasy-api/easytemplate/internal/utils"
+	"github.com/speakeasy-api/easytemplate/internal/vm"
+)
+
+// LintIssue describes a problem found while linting a template or script.
+type LintIssue struct {
+	// Line is the line number the issue was found on, or 0 if unknown.
+	Line int
+	// Message describes the issue.
+	Message string
+}
+
+var (
+	// builtinFuncs are the functions predefined by text/template.
+	builtinFuncs = map[string]struct{}{
+		"and": {}, "call": {}, "html": {}, "index": {}, "slice": {}, "js": {}, "len": {}, "not": {}, "or": {},
+		"print": {}, "printf": {}, "println": {}, "urlquery": {},
+		"eq": {}, "ge": {}, "gt": {}, "le": {}, "lt": {}, "ne": {},
+	}
+	registerTemplateFuncRegex = regexp.MustCompile(`registerTemplateFunc\(\s*["'\x60]([^"'\x60]+)["'\x60]`)
+	templateCallRegex         = regexp.MustCompile(`\b(templateFile|templateString)\(\s*["'\x60]([^"'\x60]+)["'\x60]`)
+)
+
+// DeclaredTemplateFuncs returns the names of any template functions registered with registerTemplateFunc in the provided source,
+// this can be a script or a template containing sjs blocks.
+func DeclaredTemplateFuncs(src string) []string {
+	names := []string{}
+
+	for _, match := range registerTemplateFuncRegex.FindAllStringSubmatch(src, -1) {
+		names = append(names, match[1])
+	}
+
+	return names
+}
+
+// LintScript statically validates the provided script, checking that it compiles and that any templates it references can be read.
+func (t *Templator) LintScript(name, src string) []LintIssue {
+	return t.lintScript(name, src, 1)
+}
+
+// LintTemplate statically validates the provided template without executing it. It checks that any sjs blocks compile,
+// that the template parses, that recurse is only used on the first line, that all functions used are known and
+// that any templates it references can be read. extraFuncs contains the names of any functions that will be
+// registered at runtime, for example by registerTemplateFunc.
+func (t *Templator) LintTemplate(name, input string, extraFuncs []string) []LintIssue {
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+
+	issues := []LintIssue{}
+
+	syn, rest, pragmaLines, err := t.syntaxFor(input, "")
+	if err != nil {
+		return append(issues, LintIssue{Line: 1, Message: err.Error()})
+	}
+	input = strings.Repeat("\n", pragmaLines) + rest
+
+	// firstLine is the line recurse must be used on, after any pragma or front-matter
+	firstLine := 1 + pragmaLines
+
+	if t.FrontMatter {
+		// Replace the front-matter with blank lines, so line numbers in the remaining template are preserved
+		_, rest, lines, err := splitFrontMatter(rest)
+		if err != nil {
+			return append(issues, LintIssue{Line: firstLine, Message: err.Error()})
+		}
+		input = strings.Repeat("\n", pragmaLines+lines) + rest
+		firstLine += lines
+	}
+
+	// Replace the sjs blocks with blank lines, so line numbers in the remaining template are preserved
+	stripped, _ := utils.ReplaceAllStringSubmatchFunc(syn.sjsRegex, input, func(match []string) (string, error) {
+		const expectedMatchLen = 3
+		if len(match) != expectedMatchLen {
+			return match[0], nil
+		}
+
+		issues = append(issues, t.lintScript(name, match[2], findJSBlockLineNumber(input, match[2]))...)
+
+		return strings.Repeat("\n", strings.Count(match[1], "\n")), nil
+	})
+
+	// recurse is only added to the template functions once the engine is initialized
+	known := map[string]struct{}{"recurse": {}}
+	for fn := range t.TmplFuncs {
+		known[fn] = struct{}{}
+	}
+	for _, fn := range extraFuncs {
+		known[fn] = struct{}{}
+	}
+
+	funcs := map[string]any{}
+	for fn := range known {
+		funcs[fn] = nil
+	}
+
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck | parse.ParseComments
+	treeSet := map[string]*parse.Tree{}
+	if _, err := tree.Parse(stripped, syn.leftDelim, syn.rightDelim, treeSet, funcs); err != nil {
+		return append(issues, LintIssue{Line: parseErrorLine(name, err), Message: err.Error()})
+	}
+
+	for _, tr := range treeSet {
+		walkNodes(tr.Root, func(node parse.Node) {
+			issues = append(issues, t.lintNode(name, stripped, node, known, firstLine)...)
+		})
+	}
+
+	lines := strings.Split(stripped, "\n")
+	if first := lines[firstLine-1]; strings.Contains(first, "recurse") && !syn.recurseRegex.MatchString(first) {
+		issues = append(issues, LintIssue{
+			Line:    firstLine,
+			Message: fmt.Sprintf("recurse must be on its own on the first line of the template, e.g. %s recurse 1 %s", syn.leftDelim, syn.rightDelim),
+		})
+	}
+
+	return issues
+}
+
+// BuiltinFuncNames returns the sorted names of the functions predefined by text/template.
+func BuiltinFuncNames() []string {
+	names := make([]string, 0, len(builtinFuncs))
+	for name := range builtinFuncs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *Templator) lintNode(name, input string, node parse.Node, known map[string]struct{}, firstLine int) []LintIssue {
+	issues := []LintIssue{}
+
+	cmd, ok := node.(*parse.CommandNode)
+	if !ok {
+		if ident, ok := node.(*parse.IdentifierNode); ok {
+			if _, ok := known[ident.Ident]; !ok {
+				if _, ok := builtinFuncs[ident.Ident]; !ok {
+					issues = append(issues, LintIssue{Line: lineOf(input, ident.Pos), Message: fmt.Sprintf("function %q not defined", ident.Ident)})
+				}
+			}
+		}
+
+		return issues
+	}
+
+	if len(cmd.Args) == 0 {
+		return issues
+	}
+
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return issues
+	}
+
+	line := lineOf(input, cmd.Pos)
+
+	switch ident.Ident {
+	case "recurse":
+		if line != firstLine {
+			issues = append(issues, LintIssue{Line: line, Message: "recurse can only be used on the first line of the template"})
+		}
+	case "templateFile", "templateString", "extends":
+		if len(cmd.Args) < 2 { //nolint:mnd
+			break
+		}
+		if path, ok := cmd.Args[1].(*parse.StringNode); ok {
+			if _, err := t.ReadFunc(t.resolveRef(path.Text, name)); err != nil {
+				issues = append(issues, LintIssue{Line: line, Message: fmt.Sprintf("%s references template %q which can't be read: %s", ident.Ident, path.Text, err.Error())})
+			}
+		}
+	}
+
+	return issues
+}
+
+func (t *Templator) lintScript(name, src string, startingLineNumber int) []LintIssue {
+	issues := []LintIssue{}
+
+	for _, d := range vm.Check(name, src) {
+		line := d.Line
+		if line > 0 {
+			line += startingLineNumber - 1
+		}
+		issues = append(issues, LintIssue{Line: line, Message: d.Message})
+	}
+
+	for _, match := range templateCallRegex.FindAllStringSubmatchIndex(src, -1) {
+		fn := src[match[2]:match[3]]
+		path := src[match[4]:match[5]]
+
+		if _, err := t.ReadFunc(t.resolveRef(path, name)); err != nil {
+			issues = append(issues, LintIssue{
+				Line:    lineOf(src, parse.Pos(match[0])) + startingLineNumber - 1,
+				Message: fmt.Sprintf("%s references template %q which can't be read: %s", fn, path, err.Error()),
+			})
+		}
+	}
+
+	return issues
+}
+
+// walkNodes calls fn for node and every node beneath it.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+
+	fn(node)
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkNodes(c, fn)
+		}
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkNodes(c, fn)
+		}
+	case *parse.CommandNode:
+		for _, c := range n.Args {
+			walkNodes(c, fn)
+		}
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	walkNodes(n.Pipe, fn)
+	walkNodes(n.List, fn)
+	walkNodes(n.ElseList, fn)
+}
+
+func lineOf(input string, pos parse.Pos) int {
+	if int(pos) > len(input) {
+		return 0
+	}
+
+	return strings.Count(input[:pos], "\n") + 1
+}
+
+func parseErrorLine(name string, err error) int {
+	msg := strings.TrimPrefix(err.Error(), "template: "+name+":")
+
+	line := 0
+	if _, sErr := fmt.Sscanf(msg, "%d:", &line); sErr != nil {
+		return 0
+	}
+
+	return line
+}
@@ -0,0 +1,45 @@
+package template
+
+import (
+	"strings"
+)
+
+// Normalizer configures the normalisation applied to the output of TemplateFile before it is formatted and written.
+type Normalizer struct {
+	// CollapseBlankLines collapses runs of multiple blank lines into a single blank line.
+	CollapseBlankLines bool
+	// TrimTrailingWhitespace removes whitespace from the end of every line.
+	TrimTrailingWhitespace bool
+	// FinalNewline ensures the output ends with exactly one newline.
+	FinalNewline bool
+}
+
+// Normalize returns the normalised output.
+func (n Normalizer) Normalize(out string) string {
+	if !n.CollapseBlankLines && !n.TrimTrailingWhitespace && !n.FinalNewline {
+		return out
+	}
+
+	lines := strings.Split(strings.ReplaceAll(out, "\r\n", "\n"), "\n")
+	normalized := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if n.TrimTrailingWhitespace {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		if n.CollapseBlankLines && strings.TrimSpace(line) == "" && len(normalized) > 0 && strings.TrimSpace(normalized[len(normalized)-1]) == "" {
+			continue
+		}
+
+		normalized = append(normalized, line)
+	}
+
+	out = strings.Join(normalized, "\n")
+
+	if n.FinalNewline {
+		out = strings.TrimRight(out, "\n") + "\n"
+	}
+
+	return out
+}
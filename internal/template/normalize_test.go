@@ -0,0 +1,29 @@
+package template_test
+
+import (
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate/internal/template"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	in := "a  \n\n\n\t\nb\t\n\n"
+
+	tests := []struct {
+		name       string
+		normalizer template.Normalizer
+		want       string
+	}{
+		{name: "disabled", normalizer: template.Normalizer{}, want: in},
+		{name: "collapse blank lines", normalizer: template.Normalizer{CollapseBlankLines: true}, want: "a  \n\nb\t\n"},
+		{name: "trim trailing whitespace", normalizer: template.Normalizer{TrimTrailingWhitespace: true}, want: "a\n\n\n\nb\n\n"},
+		{name: "final newline", normalizer: template.Normalizer{FinalNewline: true}, want: "a  \n\n\n\t\nb\t\n"},
+		{name: "all", normalizer: template.Normalizer{CollapseBlankLines: true, TrimTrailingWhitespace: true, FinalNewline: true}, want: "a\n\nb\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.normalizer.Normalize(in))
+		})
+	}
+}
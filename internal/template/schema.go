@@ -0,0 +1,63 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate/internal/schema"
+)
+
+// SchemaPath returns the path of the companion schema file for the provided template, ie templates/client.stmpl -> templates/client.schema.json.
+func SchemaPath(templatePath string) string {
+	return strings.TrimSuffix(templatePath, path.Ext(templatePath)) + ".schema.json"
+}
+
+func (t *Templator) validateInputData(templatePath string, inputData any) error {
+	s, err := t.loadSchema(templatePath)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+
+	if err := s.Validate(".Local", inputData); err != nil {
+		return fmt.Errorf("invalid data for template %s: %w", templatePath, err)
+	}
+
+	return nil
+}
+
+func (t *Templator) loadSchema(templatePath string) (*schema.Schema, error) {
+	if t.schemaCache == nil {
+		t.schemaCache = map[string]*schema.Schema{}
+	}
+
+	if s, ok := t.schemaCache[templatePath]; ok {
+		return s, nil
+	}
+
+	schemaPath := SchemaPath(templatePath)
+
+	data, err := t.ReadFunc(schemaPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			t.schemaCache[templatePath] = nil
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, fmt.Errorf("failed to read schema %s: %w", schemaPath, err)
+	}
+
+	s, err := schema.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", schemaPath, err)
+	}
+
+	t.schemaCache[templatePath] = s
+
+	return s, nil
+}
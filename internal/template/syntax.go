@@ -0,0 +1,155 @@
+package template
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultLeftDelim   = "{{"
+	defaultRightDelim  = "}}"
+	defaultScriptOpen  = "```sjs"
+	defaultScriptClose = "sjs```"
+)
+
+// pragmaPrefix starts a first line pragma overriding the delimiters or output mode for a single template, ie:
+//
+//	#easytemplate delims=[[,]] script=<%,%> mode=html
+const pragmaPrefix = "#easytemplate "
+
+// OutputMode determines how a template's output is escaped.
+type OutputMode int
+
+const (
+	// OutputText renders templates with text/template, without any escaping.
+	OutputText OutputMode = iota
+	// OutputHTML renders templates with html/template, contextually escaping output for HTML.
+	OutputHTML
+)
+
+// syntax holds the delimiters and output mode used by a template and the regexes derived from them.
+type syntax struct {
+	mode         OutputMode
+	leftDelim    string
+	rightDelim   string
+	scriptOpen   string
+	scriptClose  string
+	sjsRegex     *regexp.Regexp
+	recurseRegex *regexp.Regexp
+	extendsRegex *regexp.Regexp
+	definesRegex *regexp.Regexp
+}
+
+func newSyntax(leftDelim, rightDelim, scriptOpen, scriptClose string) *syntax {
+	if leftDelim == "" {
+		leftDelim = defaultLeftDelim
+	}
+	if rightDelim == "" {
+		rightDelim = defaultRightDelim
+	}
+	if scriptOpen == "" {
+		scriptOpen = defaultScriptOpen
+	}
+	if scriptClose == "" {
+		scriptClose = defaultScriptClose
+	}
+
+	left := regexp.QuoteMeta(leftDelim)
+	right := regexp.QuoteMeta(rightDelim)
+
+	return &syntax{
+		leftDelim:    leftDelim,
+		rightDelim:   rightDelim,
+		scriptOpen:   scriptOpen,
+		scriptClose:  scriptClose,
+		sjsRegex:     regexp.MustCompile(fmt.Sprintf(`(?ms)(%s\s*\n*(.*?)%s)`, regexp.QuoteMeta(scriptOpen), regexp.QuoteMeta(scriptClose))),
+		recurseRegex: regexp.MustCompile(fmt.Sprintf(`^%s-* *recurse (\d+) *-*%s$`, left, right)),
+		extendsRegex: regexp.MustCompile(fmt.Sprintf(`%s-?\s*extends\s+"([^"]+)"\s*-?%s`, left, right)),
+		definesRegex: regexp.MustCompile(fmt.Sprintf(`%s-?\s*(?:define|block)\s`, left)),
+	}
+}
+
+// LeftDelim returns the left action delimiter used by templates.
+func (t *Templator) LeftDelim() string {
+	return t.defaultSyntax().leftDelim
+}
+
+func (t *Templator) defaultSyntax() *syntax {
+	if t.syntax == nil {
+		t.syntax = newSyntax(t.Delims[0], t.Delims[1], t.ScriptDelims[0], t.ScriptDelims[1])
+		t.syntax.mode = t.OutputMode
+	}
+
+	return t.syntax
+}
+
+// withMode returns a copy of the syntax using the provided output mode.
+func (s *syntax) withMode(mode OutputMode) *syntax {
+	if s.mode == mode {
+		return s
+	}
+
+	c := *s
+	c.mode = mode
+
+	return &c
+}
+
+// syntaxFor returns the syntax for the provided template, taking into account the output file's extension and any first line pragma.
+// If a pragma is found it is removed from the returned input and the number of lines removed is returned.
+func (t *Templator) syntaxFor(input, outFile string) (*syntax, string, int, error) {
+	def := t.defaultSyntax()
+	if mode, ok := t.OutputModesByExt[strings.ToLower(path.Ext(outFile))]; ok && outFile != "" {
+		def = def.withMode(mode)
+	}
+
+	if !strings.HasPrefix(input, pragmaPrefix) {
+		return def, input, 0, nil
+	}
+
+	line, rest, _ := strings.Cut(input, "\n")
+	line = strings.TrimSuffix(line, "\r")
+
+	delims := [4]string{def.leftDelim, def.rightDelim, def.scriptOpen, def.scriptClose}
+	mode := def.mode
+
+	for _, opt := range strings.Fields(strings.TrimPrefix(line, pragmaPrefix)) {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return nil, "", 0, fmt.Errorf("invalid pragma option %q: expected key=value", opt)
+		}
+
+		if key == "mode" {
+			switch value {
+			case "text":
+				mode = OutputText
+			case "html":
+				mode = OutputHTML
+			default:
+				return nil, "", 0, fmt.Errorf("invalid pragma option %q: mode must be text or html", opt)
+			}
+			continue
+		}
+
+		open, closing, ok := strings.Cut(value, ",")
+		if !ok || open == "" || closing == "" {
+			return nil, "", 0, fmt.Errorf("invalid pragma option %q: expected key=open,close", opt)
+		}
+
+		switch key {
+		case "delims":
+			delims[0], delims[1] = open, closing
+		case "script":
+			delims[2], delims[3] = open, closing
+		default:
+			return nil, "", 0, fmt.Errorf("unknown pragma option %q", key)
+		}
+	}
+
+	syn := newSyntax(delims[0], delims[1], delims[2], delims[3])
+	syn.mode = mode
+
+	return syn, rest, 1, nil
+}
@@ -8,12 +8,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate/internal/schema"
 	"github.com/speakeasy-api/easytemplate/internal/utils"
 	"github.com/speakeasy-api/easytemplate/internal/vm"
 )
@@ -21,11 +24,31 @@ import (
 type (
 	// WriteFunc represents a function that writes a file.
 	WriteFunc func(string, []byte) error
+	// StreamWriteFunc represents a function that opens a file for writing, the returned writer is closed once the file is written.
+	StreamWriteFunc func(string) (io.WriteCloser, error)
 	// ReadFunc represents a function that reads a file.
 	ReadFunc func(string) ([]byte, error)
+	// WriteAborter is implemented by writers returned from a StreamWriteFunc that can discard a partially written file.
+	WriteAborter interface {
+		// CloseWithError is called in place of Close when the file fails to render, with the error it failed with.
+		CloseWithError(err error) error
+	}
+)
+
+const (
+	// ContextGlobal is the name of the js global holding the context of the template or script being run.
+	ContextGlobal = "context"
+	// RenderGlobal is the name of the js function rendering output from within sjs blocks.
+	RenderGlobal = "render"
 )
 
-var sjsRegex = regexp.MustCompile("(?ms)(```sjs\\s*\\n*(.*?)sjs```)")
+var (
+	// ErrUndefinedValue is returned in strict mode when an undefined js value would be rendered.
+	ErrUndefinedValue = errors.New("undefined value")
+	// ErrNoOutputFile is returned when templating a file without an output file, front-matter is enabled and the template's front-matter
+	// doesn't declare one.
+	ErrNoOutputFile = errors.New("no output file given and template declares none")
+)
 
 // Context is the context that is passed templates or js.
 type Context struct {
@@ -34,6 +57,7 @@ type Context struct {
 	GlobalComputed    goja.Value
 	LocalComputed     goja.Value
 	RecursiveComputed goja.Value
+	Meta              *Meta
 }
 
 type tmplContext struct {
@@ -42,8 +66,15 @@ type tmplContext struct {
 	GlobalComputed    any
 	LocalComputed     any
 	RecursiveComputed any
+	Meta              *Meta
 }
 
+// The names of the scripts creating the LocalComputed and RecursiveComputed contexts, marked as internal to the engine.
+const (
+	localComputedScript     = vm.InternalScriptPrefix + "localCreateComputedContextObject"
+	recursiveComputedScript = vm.InternalScriptPrefix + "recursiveCreateComputedContextObject"
+)
+
 // VM represents a virtual machine that can be used to run js.
 type VM interface {
 	Get(name string) goja.Value
@@ -54,20 +85,55 @@ type VM interface {
 
 // Templator extends the go text/template package to allow for sjs snippets.
 type Templator struct {
-	WriteFunc      WriteFunc
-	ReadFunc       ReadFunc
-	TmplFuncs      map[string]any
-	Debug          bool
+	WriteFunc WriteFunc
+	// StreamWriteFunc is used in place of WriteFunc if set, non-recursive templates are executed directly into the writer it opens,
+	// unless their output needs to be normalized or formatted.
+	StreamWriteFunc StreamWriteFunc
+	ReadFunc        ReadFunc
+	TmplFuncs       map[string]any
+	Debug           bool
+	// Strict enables strict mode, where missing map keys and undefined values rendered from sjs blocks are errors.
+	Strict bool
+	// ValidateSchemas enables validation of the data passed to template files against a companion <name>.schema.json file if one exists.
+	ValidateSchemas bool
+	// Partials are templates (keyed by name) parsed into every template, making them available via {{ template "name" . }}.
+	Partials map[string]string
+	// Delims overrides the left and right action delimiters, defaulting to {{ and }}.
+	Delims [2]string
+	// ScriptDelims overrides the markers opening and closing inline scripts, defaulting to ```sjs and sjs```.
+	ScriptDelims [2]string
+	// OutputMode is the default output mode for templates.
+	OutputMode OutputMode
+	// OutputModesByExt overrides the output mode for templates written to files with the given extensions (ie ".html").
+	OutputModesByExt map[string]OutputMode
+	// Normalizer normalises the whitespace of the output of TemplateFile before it is formatted and written.
+	Normalizer Normalizer
+	// ResolveRef resolves a reference to a template (ie ./header.stmpl) made from the template or script from, if set.
+	ResolveRef func(ref, from string) string
+	// Formatters format the output of TemplateFile before it is written, keyed by the output file's extension (ie ".go").
+	Formatters map[string]Formatter
+	// FrontMatter enables parsing of YAML (---) or TOML (+++) front-matter blocks at the start of templates.
+	FrontMatter bool
+	// rendering is the stack of templates currently being rendered
+	rendering      []string
 	contextData    any
 	globalComputed goja.Value
 	baseTemplate   *template.Template
+	partialsParsed bool
+	syntax         *syntax
+	schemaCache    map[string]*schema.Schema
 }
 
-// RebuildBaseTemplate creates a new base template from the current TmplFuncs.
-// Child templates created via baseTemplate.New(name) share the function maps
-// by pointer, avoiding per-template reflect.ValueOf and map copying overhead.
+// RebuildBaseTemplate creates a new base template from the current TmplFuncs, which holds the partials.
+// Templates are parsed into the base template so the function maps are only converted with reflect.ValueOf once,
+// only templates extending a layout or defining blocks are parsed into a clone so their blocks don't leak into other templates.
 func (t *Templator) RebuildBaseTemplate() {
-	t.baseTemplate = template.New("__base__").Funcs(t.TmplFuncs)
+	syn := t.defaultSyntax()
+	t.baseTemplate = template.New("__base__").Delims(syn.leftDelim, syn.rightDelim).Funcs(t.TmplFuncs)
+	if t.Strict {
+		t.baseTemplate.Option("missingkey=error")
+	}
+	t.partialsParsed = false
 }
 
 // SetContextData allows the setting of global context for templating.
@@ -77,75 +143,340 @@ func (t *Templator) SetContextData(contextData any, globalComputed goja.Value) {
 }
 
 // TemplateFile will template a file and write the output to outFile.
+// If outFile is empty the output file declared in the template's front-matter is used.
 func (t *Templator) TemplateFile(ctx context.Context, vm VM, templateFile, outFile string, inputData any) error {
-	output, err := t.TemplateString(ctx, vm, templateFile, inputData)
+	if t.StreamWriteFunc != nil {
+		return t.streamFile(ctx, vm, templateFile, outFile, inputData)
+	}
+
+	res, err := t.templateFile(ctx, vm, templateFile, outFile, inputData, nil)
 	if err != nil {
 		return err
 	}
 
-	if err := t.WriteFunc(outFile, []byte(output)); err != nil {
+	if res.skipped {
+		return nil
+	}
+
+	if outFile == "" {
+		outFile = res.outFile
+	}
+	if outFile == "" && t.FrontMatter {
+		return fmt.Errorf("failed to template %s: %w", templateFile, ErrNoOutputFile)
+	}
+
+	out, err := t.format(templateFile, outFile, []byte(t.Normalizer.Normalize(res.out)))
+	if err != nil {
+		return err
+	}
+
+	if err := t.WriteFunc(outFile, out); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", outFile, err)
 	}
 
 	return nil
 }
 
+// streamFile templates a file into the writer opened for outFile by StreamWriteFunc. The output is only buffered
+// if the template is recursive or its output needs to be normalized or formatted.
+func (t *Templator) streamFile(ctx context.Context, vm VM, templateFile, outFile string, inputData any) (err error) {
+	var wc io.WriteCloser
+	defer func() {
+		if wc == nil {
+			return
+		}
+		if err != nil {
+			_ = closeWithError(wc, err)
+			return
+		}
+		if cErr := wc.Close(); cErr != nil {
+			err = fmt.Errorf("failed to write file %s: %w", outFile, cErr)
+		}
+	}()
+
+	open := func(declaredOutFile string) (io.Writer, error) {
+		if outFile == "" {
+			outFile = declaredOutFile
+		}
+		if outFile == "" && t.FrontMatter {
+			return nil, fmt.Errorf("failed to template %s: %w", templateFile, ErrNoOutputFile)
+		}
+
+		if t.needsBuffering(outFile) {
+			return nil, nil
+		}
+
+		w, err := t.StreamWriteFunc(outFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write file %s: %w", outFile, err)
+		}
+		wc = w
+
+		return wc, nil
+	}
+
+	res, err := t.templateFile(ctx, vm, templateFile, outFile, inputData, open)
+	if err != nil {
+		return err
+	}
+
+	if res.skipped || res.streamed {
+		return nil
+	}
+
+	if outFile == "" {
+		outFile = res.outFile
+	}
+	if outFile == "" && t.FrontMatter {
+		return fmt.Errorf("failed to template %s: %w", templateFile, ErrNoOutputFile)
+	}
+
+	out, err := t.format(templateFile, outFile, []byte(t.Normalizer.Normalize(res.out)))
+	if err != nil {
+		return err
+	}
+
+	if err := t.Write(outFile, out); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", outFile, err)
+	}
+
+	return nil
+}
+
+// Write writes data to the named file, using StreamWriteFunc if set and WriteFunc otherwise.
+func (t *Templator) Write(name string, data []byte) error {
+	if t.StreamWriteFunc == nil {
+		return t.WriteFunc(name, data)
+	}
+
+	w, err := t.StreamWriteFunc(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		_ = closeWithError(w, err)
+		return err
+	}
+
+	return w.Close()
+}
+
+// closeWithError closes a writer after a failed write, letting it discard the partial file if it implements WriteAborter.
+func closeWithError(w io.WriteCloser, err error) error {
+	if a, ok := w.(WriteAborter); ok {
+		return a.CloseWithError(err)
+	}
+
+	return w.Close()
+}
+
+// needsBuffering returns true if the output written to outFile must be held in memory to be normalized or formatted.
+func (t *Templator) needsBuffering(outFile string) bool {
+	if t.Normalizer != (Normalizer{}) {
+		return true
+	}
+
+	_, ok := t.Formatters[strings.ToLower(path.Ext(outFile))]
+
+	return ok
+}
+
+// TemplateTo will template the provided file and write the output to w. Non-recursive templates are executed directly into w,
+// so if an error occurs w may contain partial output.
+func (t *Templator) TemplateTo(ctx context.Context, vm VM, w io.Writer, templatePath string, inputData any) error {
+	res, err := t.templateFile(ctx, vm, templatePath, "", inputData, func(string) (io.Writer, error) {
+		return w, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.skipped || res.streamed {
+		return nil
+	}
+
+	if _, err := io.WriteString(w, res.out); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
+
+// resolveRef resolves the reference made from the template or script from using ResolveRef, if set.
+func (t *Templator) resolveRef(ref, from string) string {
+	if t.ResolveRef == nil {
+		return ref
+	}
+
+	return t.ResolveRef(ref, from)
+}
+
+// CurrentTemplate returns the name of the template currently being rendered, or an empty string if none is.
+func (t *Templator) CurrentTemplate() string {
+	if len(t.rendering) == 0 {
+		return ""
+	}
+
+	return t.rendering[len(t.rendering)-1]
+}
+
+// renderResult is the result of rendering a template.
+type renderResult struct {
+	out string
+	// outFile is the rendered output file declared in the template's front-matter, if any.
+	outFile string
+	// skipped is true if the template was disabled by its front-matter.
+	skipped bool
+	// streamed is true if the output was written to the writer opened by renderOptions.open rather than returned in out.
+	streamed bool
+}
+
 type inlineScriptContext struct {
 	renderedContent []string
+	strict          bool
+	err             error
 }
 
-func newInlineScriptContext() *inlineScriptContext {
+func newInlineScriptContext(strict bool) *inlineScriptContext {
 	return &inlineScriptContext{
 		renderedContent: []string{},
+		strict:          strict,
 	}
 }
 
 func (c *inlineScriptContext) render(call goja.FunctionCall) goja.Value {
-	c.renderedContent = append(c.renderedContent, call.Argument(0).String())
+	arg := call.Argument(0)
+	if c.strict && goja.IsUndefined(arg) && c.err == nil {
+		c.err = fmt.Errorf("%w: render called with undefined", ErrUndefinedValue)
+	}
+
+	c.renderedContent = append(c.renderedContent, arg.String())
 
 	return goja.Undefined()
 }
 
 // TemplateString will template the provided file and return the output as a string.
 func (t *Templator) TemplateString(ctx context.Context, vm VM, templatePath string, inputData any) (out string, err error) {
+	res, err := t.templateFile(ctx, vm, templatePath, "", inputData, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return res.out, nil
+}
+
+func (t *Templator) templateFile(ctx context.Context, vm VM, templatePath, outFile string, inputData any, open openFunc) (*renderResult, error) {
 	data, err := t.ReadFunc(templatePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read template file: %w", err)
+		return nil, fmt.Errorf("failed to read template file: %w", err)
 	}
 
-	return t.TemplateStringInput(ctx, vm, templatePath, string(data), inputData)
+	return t.render(ctx, vm, templatePath, string(data), inputData, renderOptions{isFile: true, outFile: outFile, open: open})
 }
 
 // TemplateStringInput will template the provided input string and return the output as a string.
-//
-//nolint:funlen
 func (t *Templator) TemplateStringInput(ctx context.Context, vm VM, name string, input string, inputData any) (out string, err error) {
+	res, err := t.render(ctx, vm, name, input, inputData, renderOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return res.out, nil
+}
+
+type renderOptions struct {
+	// isFile indicates the input was read from the file name, enabling validation against a companion schema file.
+	isFile bool
+	// outFile is the file the output will be written to, if known.
+	outFile string
+	// open, if set, opens a writer the output of a non-recursive template is executed directly into.
+	open openFunc
+}
+
+// openFunc opens a writer for the output of a template, given the output file declared in its front-matter if any.
+// A nil writer indicates the output should be returned rather than streamed.
+type openFunc func(outFile string) (io.Writer, error)
+
+// render templates the provided input.
+//
+//nolint:funlen,cyclop,gocognit
+func (t *Templator) render(ctx context.Context, vm VM, name string, input string, inputData any, opts renderOptions) (res *renderResult, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			err = fmt.Errorf("failed to render template: %s", e)
 		}
 	}()
 
-	localComputed, err := vm.Run(ctx, "localCreateComputedContextObject", `createComputedContextObject();`)
+	res = &renderResult{}
+
+	t.rendering = append(t.rendering, name)
+	defer func() { t.rendering = t.rendering[:len(t.rendering)-1] }()
+
+	syn, input, lineOffset, err := t.syntaxFor(input, opts.outFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	var meta *Meta
+
+	if t.FrontMatter {
+		var frontMatterLines int
+		meta, input, frontMatterLines, err = splitFrontMatter(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+		}
+		lineOffset += frontMatterLines
+
+		inputData = meta.applyDefaults(inputData)
+
+		if err := meta.checkRequired(name, inputData); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.isFile && t.ValidateSchemas {
+		if err := t.validateInputData(name, inputData); err != nil {
+			return nil, err
+		}
+	}
+
+	if meta != nil {
+		enabled, err := t.isEnabled(syn, name, meta, &tmplContext{Global: t.contextData, Local: inputData, Meta: meta})
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+		}
+		if !enabled {
+			res.skipped = true
+			return res, nil
+		}
+	}
+
+	localComputed, err := vm.Run(ctx, localComputedScript, `createComputedContextObject();`)
 	if err != nil {
-		return "", utils.HandleJSError("failed to create local computed context", err)
+		return nil, utils.HandleJSError("failed to create local computed context", err)
 	}
 
-	currentContext := vm.Get("context")
+	currentContext := vm.Get(ContextGlobal)
 
 	currentRecursiveComputed := getRecursiveComputedContext(vm)
 	localRecursiveComputed := currentRecursiveComputed
 
 	numIterations := 1
-	numRecursions, err := t.isRecursiveTemplate(input)
+	numRecursions, err := t.isRecursiveTemplate(syn, input)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if meta != nil && meta.Recurse > 0 {
+		numRecursions = meta.Recurse
+		// Equivalent to the output of {{ recurse n }}, without affecting line numbers
+		input = fmt.Sprintf(canaryPlaceholder, strconv.Itoa(numRecursions-1)) + input
 	}
 	if numRecursions > 0 {
 		numIterations = numRecursions + 1
-		localRecursiveComputed, err = vm.Run(ctx, "recursiveCreateComputedContextObject", `createComputedContextObject();`)
+		localRecursiveComputed, err = vm.Run(ctx, recursiveComputedScript, `createComputedContextObject();`)
 		if err != nil {
-			return "", utils.HandleJSError("failed to create recursive computed context", err)
+			return nil, utils.HandleJSError("failed to create recursive computed context", err)
 		}
 	}
 
@@ -156,15 +487,16 @@ func (t *Templator) TemplateStringInput(ctx context.Context, vm VM, name string,
 			Local:             inputData,
 			LocalComputed:     localComputed,
 			RecursiveComputed: localRecursiveComputed,
+			Meta:              meta,
 		}
 
-		if err := vm.Set("context", context); err != nil {
-			return "", fmt.Errorf("failed to set context: %w", err)
+		if err := vm.Set(ContextGlobal, context); err != nil {
+			return nil, fmt.Errorf("failed to set context: %w", err)
 		}
 
-		evaluated, replacedLines, err := t.evaluateInlineScripts(ctx, vm, name, input)
+		evaluated, replacedLines, err := t.evaluateInlineScripts(ctx, vm, syn, name, input, lineOffset)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		// Get the computed context back as it might have been modified by the inline script
@@ -176,44 +508,69 @@ func (t *Templator) TemplateStringInput(ctx context.Context, vm VM, name string,
 			GlobalComputed:    context.GlobalComputed.Export(),
 			LocalComputed:     localComputed.Export(),
 			RecursiveComputed: localRecursiveComputed.Export(),
+			Meta:              meta,
+		}
+
+		if meta != nil && meta.Output != "" {
+			res.outFile, err = t.execTemplate(syn.withMode(OutputText), name+":output", meta.Output, tmplCtx, 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render output file: %w", err)
+			}
 		}
 
-		out, err = t.execTemplate(name, evaluated, tmplCtx, replacedLines)
+		if opts.open != nil && numIterations == 1 {
+			w, err := opts.open(res.outFile)
+			if err != nil {
+				return nil, err
+			}
+
+			if w != nil {
+				if err := t.execTemplateTo(w, syn, name, evaluated, tmplCtx, replacedLines+lineOffset); err != nil {
+					return nil, err
+				}
+
+				res.streamed = true
+
+				break
+			}
+		}
+
+		res.out, err = t.execTemplate(syn, name, evaluated, tmplCtx, replacedLines+lineOffset)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		// Set the output as the input for the next iteration and update the computed context
 		var cont bool
-		input, cont, err = t.applyRecurseCanary(out)
+		input, cont, err = t.applyRecurseCanary(res.out)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		// If the output is the same as the input, or no canary found, we don't need to continue
-		if !cont || evaluated == out {
+		if !cont || evaluated == res.out {
 			break
 		}
 		localRecursiveComputed = getRecursiveComputedContext(vm)
 	}
 
 	// Reset the context back to the previous one
-	if err := vm.Set("context", currentContext); err != nil {
-		return "", fmt.Errorf("failed to reset context: %w", err)
+	if err := vm.Set(ContextGlobal, currentContext); err != nil {
+		return nil, fmt.Errorf("failed to reset context: %w", err)
 	}
 
-	return out, nil
+	return res, nil
 }
 
-func (t *Templator) evaluateInlineScripts(ctx context.Context, vm VM, templatePath, content string) (string, int, error) {
+func (t *Templator) evaluateInlineScripts(ctx context.Context, vm VM, syn *syntax, templatePath, content string, lineOffset int) (string, int, error) {
 	replacedLines := 0
 
-	evaluated, err := utils.ReplaceAllStringSubmatchFunc(sjsRegex, content, func(match []string) (string, error) {
+	evaluated, err := utils.ReplaceAllStringSubmatchFunc(syn.sjsRegex, content, func(match []string) (string, error) {
 		const expectedMatchLen = 3
 		if len(match) != expectedMatchLen {
 			return match[0], nil
 		}
 
-		output, err := t.execSJSBlock(ctx, vm, match[2], templatePath, findJSBlockLineNumber(content, match[2]))
+		output, err := t.execSJSBlock(ctx, vm, syn, match[2], templatePath, findJSBlockLineNumber(content, match[2])+lineOffset)
 		if err != nil {
 			return "", err
 		}
@@ -229,28 +586,32 @@ func (t *Templator) evaluateInlineScripts(ctx context.Context, vm VM, templatePa
 	return evaluated, replacedLines, nil
 }
 
-func (t *Templator) execSJSBlock(ctx context.Context, v VM, js, templatePath string, jsBlockLineNumber int) (string, error) {
-	currentRender := v.Get("render")
+func (t *Templator) execSJSBlock(ctx context.Context, v VM, syn *syntax, js, templatePath string, jsBlockLineNumber int) (string, error) {
+	currentRender := v.Get(RenderGlobal)
 
-	c := newInlineScriptContext()
-	if err := v.Set("render", c.render); err != nil {
+	c := newInlineScriptContext(t.Strict)
+	if err := v.Set(RenderGlobal, c.render); err != nil {
 		return "", fmt.Errorf("failed to set render function: %w", err)
 	}
 
 	if _, err := v.Run(ctx, templatePath, js, vm.WithStartingLineNumber(jsBlockLineNumber)); err != nil {
-		return "", fmt.Errorf("failed to run inline script in %s:\n```sjs\n%ssjs```\n%w", templatePath, js, err)
+		return "", fmt.Errorf("failed to run inline script in %s:\n%s\n%s%s\n%w", templatePath, syn.scriptOpen, js, syn.scriptClose, err)
 	}
 
-	if err := v.Set("render", currentRender); err != nil {
+	if err := v.Set(RenderGlobal, currentRender); err != nil {
 		return "", fmt.Errorf("failed to unset render function: %w", err)
 	}
 
+	if c.err != nil {
+		return "", fmt.Errorf("failed to run inline script in %s:\n%s\n%s%s\n%w", templatePath, syn.scriptOpen, js, syn.scriptClose, c.err)
+	}
+
 	return strings.Join(c.renderedContent, "\n"), nil
 }
 
 func getLocalComputedContext(vm VM) goja.Value {
 	// Get the local context back as it might have been modified by the inline script
-	contextVal := vm.Get("context")
+	contextVal := vm.Get(ContextGlobal)
 
 	computedVal := vm.ToObject(contextVal).Get("LocalComputed")
 
@@ -258,7 +619,7 @@ func getLocalComputedContext(vm VM) goja.Value {
 }
 
 func getRecursiveComputedContext(vm VM) goja.Value {
-	contextVal := vm.Get("context")
+	contextVal := vm.Get(ContextGlobal)
 	if contextVal == goja.Undefined() {
 		return goja.Undefined()
 	}
@@ -268,28 +629,55 @@ func getRecursiveComputedContext(vm VM) goja.Value {
 	return computedVal
 }
 
-func (t *Templator) execTemplate(name string, tmplContent string, data any, replacedLines int) (string, error) {
+func (t *Templator) execTemplate(syn *syntax, name string, tmplContent string, data any, replacedLines int) (string, error) {
+	var buf bytes.Buffer
+
+	if err := t.execTemplateTo(&buf, syn, name, tmplContent, data, replacedLines); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// execTemplateTo executes the template directly into w, anything already written is left in w if execution fails.
+func (t *Templator) execTemplateTo(w io.Writer, syn *syntax, name string, tmplContent string, data any, replacedLines int) error {
 	if t.baseTemplate == nil {
 		t.RebuildBaseTemplate()
 	}
 
-	tmp, err := t.baseTemplate.New(name).Parse(tmplContent)
+	if syn.mode == OutputHTML {
+		return t.execHTMLTemplateTo(w, syn, name, tmplContent, data, replacedLines)
+	}
+
+	if err := t.parsePartials(); err != nil {
+		return err
+	}
+
+	base, layout, err := t.parseLayouts(syn, name, tmplContent)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := base.New(name).Delims(syn.leftDelim, syn.rightDelim).Parse(tmplContent)
 	if err != nil {
 		if t.Debug {
 			//nolint:forbidigo
 			fmt.Println(tmplContent)
 		}
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	var buf bytes.Buffer
+	if layout != nil {
+		// The template's blocks have been parsed over the layout's, so the layout is what is executed
+		tmp = layout
+	}
 
-	if err := tmp.Execute(&buf, data); err != nil {
+	if err := tmp.Execute(w, data); err != nil {
 		err = adjustLineNumber(name, err, replacedLines)
-		return "", fmt.Errorf("failed to execute template: %w", err)
+		return fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return buf.String(), nil
+	return nil
 }
 
 // Recurse will let the engine know how many times the template should execute.
@@ -304,13 +692,12 @@ func (t *Templator) Recurse(_ VM, numTimes int) (out string, err error) {
 var (
 	canaryPlaceholder = "~-~SPEAKEASY_RECURSE_CANARY_%s~-~"
 	canaryRegex       = regexp.MustCompile(fmt.Sprintf(canaryPlaceholder, `(\d+)`))
-	recurseRegex      = regexp.MustCompile(`^{{-* *recurse (\d+) *-*}}$`)
 )
 
-func (t *Templator) isRecursiveTemplate(input string) (int, error) {
+func (t *Templator) isRecursiveTemplate(syn *syntax, input string) (int, error) {
 	lines := strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")
 
-	matches := recurseRegex.FindAllStringSubmatch(lines[0], -1)
+	matches := syn.recurseRegex.FindAllStringSubmatch(lines[0], -1)
 	if len(matches) != 1 {
 		return -1, nil
 	}
@@ -58,7 +58,7 @@ func TestTemplator_TemplateFile_Success(t *testing.T) {
 			o := goja.New()
 			contextVal := o.ToValue(ctx)
 
-			vm.EXPECT().Run(context.Background(), "localCreateComputedContextObject", `createComputedContextObject();`).Return(goja.Undefined(), nil).Times(1)
+			vm.EXPECT().Run(context.Background(), "easytemplate:localCreateComputedContextObject", `createComputedContextObject();`).Return(goja.Undefined(), nil).Times(1)
 			vm.EXPECT().Get("context").Return(goja.Undefined()).Times(2)
 			vm.EXPECT().Set("context", ctx).Return(nil).Times(1)
 			vm.EXPECT().Get("context").Return(contextVal).Times(1)
@@ -158,7 +158,7 @@ func TestTemplator_TemplateString_Success(t *testing.T) {
 			o := goja.New()
 			contextVal := o.ToValue(ctx)
 
-			vm.EXPECT().Run(context.Background(), "localCreateComputedContextObject", `createComputedContextObject();`).Return(goja.Undefined(), nil).Times(1)
+			vm.EXPECT().Run(context.Background(), "easytemplate:localCreateComputedContextObject", `createComputedContextObject();`).Return(goja.Undefined(), nil).Times(1)
 			vm.EXPECT().Get("context").Return(goja.Undefined()).Times(2)
 			vm.EXPECT().Set("context", ctx).Return(nil).Times(1)
 
@@ -0,0 +1,23 @@
+// Package tooling gives the easytemplate command's tooling (ie the language server) access to parts of the engine that aren't part
+// of its public API.
+package tooling
+
+import "github.com/speakeasy-api/easytemplate/internal/template"
+
+// Engine is the view of an *easytemplate.Engine used by tooling.
+type Engine interface {
+	// TemplateFuncNames returns the sorted names of the functions available to templates, excluding those predefined by text/template.
+	TemplateFuncNames() []string
+	// JSGlobalNames returns the sorted names of the globals available to javascript.
+	JSGlobalNames() []string
+	// IsJSVariable returns true if the named javascript global isn't a function.
+	IsJSVariable(name string) bool
+	// LintSource lints the contents of a template or script in the same way as Engine.Lint, without reading it from file.
+	LintSource(file, src string) []template.LintIssue
+	// Locate returns the path the file referenced by ref from the template or script from is read from.
+	Locate(ref, from string) (string, error)
+}
+
+// Of returns the tooling view of e, which must be an *easytemplate.Engine. It's set by the easytemplate package, which can't be
+// imported here.
+var Of func(e any) Engine
@@ -4,7 +4,9 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"path"
 	"regexp"
+	"strings"
 
 	"github.com/dop251/goja"
 )
@@ -46,3 +48,35 @@ func HandleJSError(msg string, err error) error {
 
 	return fmt.Errorf("%s: %s", msg, jsErr.String())
 }
+
+// MatchGlob reports whether the slash separated name matches the pattern. The pattern syntax is that of path.Match,
+// with the addition of ** matching any number of path segments (including none).
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0
+}
@@ -40,3 +40,25 @@ func TestReplaceAllStringSubmatchFunc_Success(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.stmpl", name: "test.stmpl", want: true},
+		{pattern: "*.stmpl", name: "dir/test.stmpl", want: false},
+		{pattern: "**/*.stmpl", name: "test.stmpl", want: true},
+		{pattern: "**/*.stmpl", name: "a/b/test.stmpl", want: true},
+		{pattern: "a/**", name: "a/b/c.txt", want: true},
+		{pattern: "a/**/c.txt", name: "a/c.txt", want: true},
+		{pattern: "a/**/c.txt", name: "b/c.txt", want: false},
+		{pattern: "a/?.txt", name: "a/b.txt", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.MatchGlob(tt.pattern, tt.name))
+		})
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -33,9 +33,10 @@ var (
 
 const (
 	sleepThreshold = 50 * time.Millisecond
-)
 
-var lineNumberRegex = regexp.MustCompile(` \(*([^ ]+):([0-9]+):([0-9]+)\([0-9]+\)`)
+	// InternalScriptPrefix prefixes the names of the scripts run by the engine itself, rather than loaded from a file.
+	InternalScriptPrefix = "easytemplate:"
+)
 
 type transformCacheKey struct {
 	name string
@@ -45,9 +46,8 @@ type transformCacheKey struct {
 // VM is a wrapper around the goja runtime.
 type VM struct {
 	*goja.Runtime
-	globalSourceMapCache map[string]*sourcemap.Consumer
-	transformCache       map[transformCacheKey]*esbuild.TransformResult
-	transformCacheMutex  sync.RWMutex
+	transformCache      map[transformCacheKey]*esbuild.TransformResult
+	transformCacheMutex sync.RWMutex
 }
 
 // Options represents options for running a script.
@@ -61,7 +61,9 @@ type Option func(*Options)
 // RandSource is a function that returns a seeded float64 value.
 type RandSource func() float64
 
-// WithStartingLineNumber sets the starting line number for the script.
+// WithStartingLineNumber sets the line number the script starts on within its file, ie for an inline script within a template.
+// The script is compiled as if preceded by blank lines, so its source map, stack traces and debugger breakpoints use the line
+// numbers of the file.
 func WithStartingLineNumber(lineNumber int) Option {
 	return func(o *Options) {
 		o.startingLineNumber = lineNumber
@@ -76,7 +78,7 @@ type program struct {
 // New creates a new VM.
 func New(randSource RandSource) (*VM, error) {
 	g := goja.New()
-	_, err := g.RunString(underscore.JS)
+	_, err := g.RunScript(InternalScriptPrefix+"underscore.js", underscore.JS)
 	if err != nil {
 		return nil, utils.HandleJSError("failed to init underscore", err)
 	}
@@ -91,9 +93,8 @@ func New(randSource RandSource) (*VM, error) {
 	}
 
 	return &VM{
-		Runtime:              g,
-		globalSourceMapCache: make(map[string]*sourcemap.Consumer),
-		transformCache:       make(map[transformCacheKey]*esbuild.TransformResult),
+		Runtime:        g,
+		transformCache: make(map[transformCacheKey]*esbuild.TransformResult),
 	}, nil
 }
 
@@ -104,6 +105,10 @@ func (v *VM) Run(ctx context.Context, name string, src string, opts ...Option) (
 		opt(options)
 	}
 
+	if options.startingLineNumber > 1 {
+		src = strings.Repeat("\n", options.startingLineNumber-1) + src
+	}
+
 	p, err := v.compile(name, src, true)
 	if err != nil {
 		return nil, err
@@ -116,7 +121,6 @@ func (v *VM) Run(ctx context.Context, name string, src string, opts ...Option) (
 				return nil, fmt.Errorf("failed to compile source map for script: %w", err)
 			}
 		} else {
-			v.globalSourceMapCache[name] = m
 			// Attach source map to the program so goja natively resolves
 			// positions (enables debugger breakpoints on TS source lines).
 			p.prog.SetSourceMap(m)
@@ -135,11 +139,8 @@ func (v *VM) Run(ctx context.Context, name string, src string, opts ...Option) (
 		return nil, fmt.Errorf("failed to run script: %w", err)
 	}
 
-	errString := jsErr.String()
-
-	fixedStackTrace, _ := utils.ReplaceAllStringSubmatchFunc(lineNumberRegex, errString, v.remapLineNumbers(name, options.startingLineNumber))
-
-	return nil, fmt.Errorf("failed to run script %s: %w", fixedStackTrace, ErrRuntime)
+	// Positions in the stack trace have already been resolved through the source map
+	return nil, fmt.Errorf("failed to run script %s: %w", jsErr.String(), ErrRuntime)
 }
 
 // RunFunction will run the named function if it already exists within the environment, for example if it was defined in a script run by RunScript.
@@ -203,12 +204,7 @@ func (v *VM) cachedTransform(name string, src string) *esbuild.TransformResult {
 	}
 	v.transformCacheMutex.RUnlock()
 
-	result := esbuild.Transform(src, esbuild.TransformOptions{
-		Target:     esbuild.ES2015,
-		Loader:     esbuild.LoaderTS,
-		Sourcemap:  esbuild.SourceMapExternal,
-		Sourcefile: name,
-	})
+	result := transform(name, src)
 
 	v.transformCacheMutex.Lock()
 	v.transformCache[key] = &result
@@ -217,6 +213,49 @@ func (v *VM) cachedTransform(name string, src string) *esbuild.TransformResult {
 	return &result
 }
 
+func transform(name string, src string) esbuild.TransformResult {
+	return esbuild.Transform(src, esbuild.TransformOptions{
+		Target:    esbuild.ES2015,
+		Loader:    esbuild.LoaderTS,
+		Sourcemap: esbuild.SourceMapExternal,
+		// Positions are resolved to sources relative to the script's directory, so only the file name is used as the source
+		Sourcefile: path.Base(filepath.ToSlash(name)),
+	})
+}
+
+// Diagnostic describes a problem found while checking a script.
+type Diagnostic struct {
+	// Line is the line number in the original source, or 0 if unknown.
+	Line int
+	// Column is the column number in the original source, or 0 if unknown.
+	Column int
+	// Message describes the problem.
+	Message string
+}
+
+// Check compiles the provided script without running it and returns any problems found.
+func Check(name string, src string) []Diagnostic {
+	result := transform(name, src)
+	if len(result.Errors) > 0 {
+		diags := make([]Diagnostic, 0, len(result.Errors))
+		for _, errMsg := range result.Errors {
+			d := Diagnostic{Message: errMsg.Text}
+			if errMsg.Location != nil {
+				d.Line = errMsg.Location.Line
+				d.Column = errMsg.Location.Column
+			}
+			diags = append(diags, d)
+		}
+		return diags
+	}
+
+	if _, err := goja.Compile(name, string(result.Code), true); err != nil {
+		return []Diagnostic{{Message: err.Error()}}
+	}
+
+	return nil
+}
+
 func (v *VM) compile(name string, src string, strict bool) (*program, error) {
 	// transform src with esbuild -- this ensures we handle typescript
 	result := v.cachedTransform(name, src)
@@ -244,43 +283,3 @@ func (v *VM) compile(name string, src string, strict bool) (*program, error) {
 		sourceMap: result.Map,
 	}, nil
 }
-
-func (v *VM) remapLineNumbers(name string, startingLineNumber int) func(match []string) (string, error) {
-	return func(match []string) (string, error) {
-		const expectedMatches = 4
-
-		if len(match) != expectedMatches {
-			return match[0], nil
-		}
-
-		file := match[1]
-
-		sm, ok := v.globalSourceMapCache[file]
-		if !ok {
-			return match[0], nil
-		}
-
-		remappedSuffix := ""
-		line, err := strconv.Atoi(match[2])
-		if err != nil {
-			return match[0], nil //nolint:nilerr
-		}
-		column, err := strconv.Atoi(match[3])
-		if err != nil {
-			return match[0], nil //nolint:nilerr
-		}
-
-		_, _, remappedLine, remappedColumn, ok := sm.Source(line, column)
-		if ok {
-			line = remappedLine
-			column = remappedColumn
-			remappedSuffix = ":*"
-		}
-
-		if file == name && startingLineNumber > 0 {
-			line += startingLineNumber - 1
-		}
-
-		return strings.Replace(match[0], fmt.Sprintf(":%s:%s", match[2], match[3]), fmt.Sprintf(":%d:%d%s", line, column, remappedSuffix), 1), nil
-	}
-}
@@ -47,3 +47,15 @@ test({ Name: "test" });`
 	_, err = v.Run(context.Background(), "test", typeScript)
 	assert.Equal(t, "failed to run script Error: test error\n\tat test (test:5:7(3))\n\tat test:8:5(6)\n: script runtime failure", err.Error())
 }
+
+func TestVM_Run_Runtime_Errors_WithStartingLineNumber(t *testing.T) {
+	v, err := vm.New(nil)
+	require.NoError(t, err)
+
+	js := `var a = 1;
+throw new Error("test error");`
+
+	_, err = v.Run(context.Background(), "templates/test.stmpl", js, vm.WithStartingLineNumber(10))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at templates/test.stmpl:11:")
+}
@@ -0,0 +1,167 @@
+package easytemplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate/internal/vm"
+)
+
+// WithLayers sets layered file systems to read templates, scripts and other files from, in place of a single file system set with
+// WithReadFileSystem (the two can't be combined). Each layer is searched in order, along with the search locations within it, and
+// earlier layers shadow files at the same path in later ones. This allows files on disk to take precedence over embedded defaults,
+// or a stock template pack to be customised by overriding individual files in a higher layer, with the override able to render the
+// file it replaces with templateParent.
+//
+// Use OSLayer (or nil) for a layer reading from disk the same way files are read without a read file system, so absolute paths and
+// paths starting with ../ can be read. Embedded file systems can be rooted at the embedded directory with fs.Sub.
+//
+// Example:
+//
+//	stock, _ := fs.Sub(embedded, "templates")
+//	e := easytemplate.New(easytemplate.WithLayers(easytemplate.OSLayer, stock))
+func WithLayers(layers ...fs.FS) Opt {
+	return func(e *Engine) {
+		if e.readFS != nil {
+			panic(fmt.Errorf("%w: WithLayers can't be combined with WithReadFileSystem", ErrInvalidArg))
+		}
+
+		e.layers = make([]fs.FS, 0, len(layers))
+		for _, layer := range layers {
+			if layer == nil {
+				layer = OSLayer
+			}
+			e.layers = append(e.layers, layer)
+		}
+	}
+}
+
+// layerFrame records the layer a template being rendered by templateParent was read from.
+type layerFrame struct {
+	// path is the path the template resolves to through all the layers
+	path  string
+	layer int
+	// file is the path the template resolves to within the layer, which may be in a different search location to path
+	file string
+}
+
+// ResolvedPath returns the path the named file resolves to through the search locations, and the index of the layer
+// (see WithLayers) it was found in. The layer is -1 if layers aren't being used.
+func (e *Engine) ResolvedPath(name string) (string, int, error) {
+	filePath := e.resolvePath(name)
+
+	if len(e.layers) == 0 {
+		if _, err := e.statFile(filePath); err != nil {
+			return "", -1, err
+		}
+
+		return filePath, -1, nil
+	}
+
+	layer, err := layerOf(e.layers, filePath, 0, "stat")
+	if err != nil {
+		return "", -1, err
+	}
+
+	return filePath, layer, nil
+}
+
+// templateParent renders the template currently being rendered from the next layer down, so an override can extend the template it replaces.
+// The parent is rendered as a template file, so its front-matter and schema are processed as they would be for any other file.
+func (e *Engine) templateParent(ctx context.Context, v *vm.VM, data any) (string, error) {
+	name := e.templator.CurrentTemplate()
+	if name == "" {
+		return "", fmt.Errorf("%w: templateParent can only be called while rendering a template", ErrInvalidArg)
+	}
+
+	if len(e.layers) == 0 {
+		return "", fmt.Errorf("%w: templateParent requires layers, see WithLayers", ErrInvalidArg)
+	}
+
+	filePath := e.resolvePath(name)
+
+	// The current template was either read by templateParent from a known layer, or from the highest layer containing it
+	var current int
+	if len(e.layerStack) > 0 && e.layerStack[len(e.layerStack)-1].path == filePath {
+		current = e.layerStack[len(e.layerStack)-1].layer
+	} else {
+		var err error
+		if current, err = layerOf(e.layers, filePath, 0, "open"); err != nil {
+			return "", fmt.Errorf("failed to find template %s in any layer: %w", name, err)
+		}
+	}
+
+	parent, parentPath, err := e.findInLayers(name, current+1)
+	if err != nil {
+		return "", fmt.Errorf("failed to find parent of template %s below layer %d: %w", name, current, err)
+	}
+
+	frame := layerFrame{path: filePath, layer: parent, file: parentPath}
+
+	e.layerStack = append(e.layerStack, frame)
+	defer func() { e.layerStack = e.layerStack[:len(e.layerStack)-1] }()
+
+	// The next read of the template is taken from the parent's layer, see readFileFrom
+	e.parentRead = &frame
+	defer func() { e.parentRead = nil }()
+
+	return e.templator.TemplateString(ctx, v, name, data)
+}
+
+func (e *Engine) templateParentJS(call CallContext) goja.Value {
+	output, err := e.templateParent(call.Ctx, call.VM, call.Argument(0).Export())
+	if err != nil {
+		panic(call.VM.NewGoError(err))
+	}
+
+	return call.VM.ToValue(output)
+}
+
+// findInLayers returns the index of the first of the layers at or below from that contains the named file, and the path it resolves to
+// within that layer. The search locations are tried within each layer, so the file may resolve to a different path than it does in the layers above.
+func (e *Engine) findInLayers(name string, from int) (int, string, error) {
+	candidates := e.searchPaths(e.expandAlias(name))
+
+	for i := from; i < len(e.layers); i++ {
+		for _, candidate := range candidates {
+			if !inLayer(e.layers[i], candidate) {
+				continue
+			}
+
+			if _, err := fs.Stat(e.layers[i], candidate); err == nil {
+				return i, candidate, nil
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return -1, "", err
+			}
+		}
+	}
+
+	return -1, "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// layerOf returns the index of the first of the layers at or below from that contains name.
+func layerOf(layers []fs.FS, name string, from int, op string) (int, error) {
+	valid := false
+
+	for i := from; i < len(layers); i++ {
+		if !inLayer(layers[i], name) {
+			continue
+		}
+		valid = true
+
+		if _, err := fs.Stat(layers[i], name); err == nil {
+			return i, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return -1, err
+		}
+	}
+
+	if !valid {
+		return -1, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	return -1, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,264 @@
+package easytemplate_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateFile_Layers(t *testing.T) {
+	written := map[string]string{}
+
+	stock := fstest.MapFS{
+		"templates/page.stmpl":   {Data: []byte("<page>{{ templateString \"templates/header.stmpl\" .Local }}</page>")},
+		"templates/header.stmpl": {Data: []byte("stock header {{ .Local.title }}")},
+	}
+	theme := fstest.MapFS{
+		"templates/header.stmpl": {Data: []byte("[theme {{ templateParent .Local }}]")},
+	}
+	overrides := fstest.MapFS{
+		"templates/header.stmpl": {Data: []byte("(override {{ templateParent .Local }})")},
+	}
+
+	e := easytemplate.New(
+		easytemplate.WithLayers(overrides, theme, stock),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateFile(context.Background(), "templates/page.stmpl", "page.html", map[string]any{"title": "Home"})
+	require.NoError(t, err)
+	assert.Equal(t, "<page>(override [theme stock header Home])</page>", written["page.html"])
+
+	resolved, layer, err := e.ResolvedPath("templates/header.stmpl")
+	require.NoError(t, err)
+	assert.Equal(t, "templates/header.stmpl", resolved)
+	assert.Equal(t, 0, layer)
+
+	_, layer, err = e.ResolvedPath("templates/page.stmpl")
+	require.NoError(t, err)
+	assert.Equal(t, 2, layer)
+
+	out, err := e.TemplateString(context.Background(), "templates/page.stmpl", map[string]any{"title": "About"})
+	require.NoError(t, err)
+	assert.Equal(t, "<page>(override [theme stock header About])</page>", out)
+
+	_, err = e.TemplateStringInput(context.Background(), "templates/page.stmpl", "{{ templateParent .Local }}", nil)
+	require.ErrorContains(t, err, "failed to find parent of template templates/page.stmpl")
+}
+
+func TestEngine_TemplateString_LayersParentFromJS(t *testing.T) {
+	stock := fstest.MapFS{
+		"model.stmpl": {Data: []byte("---\nrequired: [name]\nlocal:\n  kind: model\n---\n{{ .Local.kind }} {{ .Local.name }}")},
+	}
+	overrides := fstest.MapFS{
+		"model.stmpl": {Data: []byte("```sjs\nrender(\"// \" + templateParent(context.Local));\nsjs```")},
+	}
+
+	e := easytemplate.New(easytemplate.WithFrontMatter(), easytemplate.WithLayers(overrides, stock))
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	out, err := e.TemplateString(context.Background(), "model.stmpl", map[string]any{"name": "user"})
+	require.NoError(t, err)
+	assert.Equal(t, "// model user", out)
+
+	// The parent is rendered as a file, so its front-matter is checked
+	_, err = e.TemplateString(context.Background(), "model.stmpl", map[string]any{})
+	assert.ErrorContains(t, err, "template model.stmpl requires data key name")
+}
+
+func TestEngine_TemplateString_LayersSearchLocations(t *testing.T) {
+	local := fstest.MapFS{
+		"main.stmpl": {Data: []byte("local main {{ templateString \"partial.stmpl\" .Local }}")},
+	}
+	embedded := fstest.MapFS{
+		"templates/main.stmpl":    {Data: []byte("embedded main")},
+		"templates/partial.stmpl": {Data: []byte("embedded partial")},
+	}
+
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithLayers(local, embedded),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	out, err := e.TemplateString(context.Background(), "main.stmpl", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "local main embedded partial", out)
+
+	_, err = e.TemplateString(context.Background(), "missing.stmpl", nil)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, "tried: templates/missing.stmpl (file system 0), missing.stmpl (file system 0), templates/missing.stmpl (file system 1), missing.stmpl (file system 1)")
+}
+
+func TestEngine_WithLayers_ConflictsWithReadFileSystem(t *testing.T) {
+	assert.PanicsWithError(t, "invalid argument: WithLayers can't be combined with WithReadFileSystem", func() {
+		easytemplate.New(easytemplate.WithReadFileSystem(fstest.MapFS{}), easytemplate.WithLayers(fstest.MapFS{}))
+	})
+	assert.PanicsWithError(t, "invalid argument: WithReadFileSystem can't be combined with WithLayers", func() {
+		easytemplate.New(easytemplate.WithLayers(fstest.MapFS{}), easytemplate.WithReadFileSystem(fstest.MapFS{}))
+	})
+}
+
+func TestEngine_TemplateString_OSLayer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "overrides"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "overrides", "main.stmpl"), []byte("disk main, {{ templateString \"../shared.stmpl\" .Local }}, {{ templateString \"partial.stmpl\" .Local }}"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared.stmpl"), []byte("disk shared"), os.ModePerm))
+
+	// Embedded file systems are rooted at the embedded directory, ie templates for //go:embed templates
+	embedded, err := fs.Sub(fstest.MapFS{
+		"templates/main.stmpl":    {Data: []byte("embedded main")},
+		"templates/partial.stmpl": {Data: []byte("embedded partial")},
+	}, "templates")
+	require.NoError(t, err)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	rel, err := filepath.Rel(cwd, filepath.Join(dir, "shared.stmpl"))
+	require.NoError(t, err)
+
+	type args struct {
+		layers []fs.FS
+		name   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOut string
+	}{
+		{
+			name: "disk override of embedded template",
+			args: args{
+				layers: []fs.FS{easytemplate.OSLayer, embedded},
+				name:   "main.stmpl",
+			},
+			wantOut: "disk main, disk shared, embedded partial",
+		},
+		{
+			name: "nil layer reads from disk",
+			args: args{
+				layers: []fs.FS{nil, embedded},
+				name:   "main.stmpl",
+			},
+			wantOut: "disk main, disk shared, embedded partial",
+		},
+		{
+			name: "embedded template without override",
+			args: args{
+				layers: []fs.FS{easytemplate.OSLayer, embedded},
+				name:   "partial.stmpl",
+			},
+			wantOut: "embedded partial",
+		},
+		{
+			name: "absolute path",
+			args: args{
+				layers: []fs.FS{easytemplate.OSLayer, embedded},
+				name:   filepath.ToSlash(filepath.Join(dir, "shared.stmpl")),
+			},
+			wantOut: "disk shared",
+		},
+		{
+			name: "relative path outside the working directory",
+			args: args{
+				layers: []fs.FS{easytemplate.OSLayer, embedded},
+				name:   filepath.ToSlash(rel),
+			},
+			wantOut: "disk shared",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(
+				easytemplate.WithSearchLocations([]string{filepath.ToSlash(filepath.Join(dir, "overrides"))}),
+				easytemplate.WithLayers(tt.args.layers...),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			out, err := e.TemplateString(context.Background(), tt.args.name, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestEngine_TemplateString_LayersParentSearchLocations(t *testing.T) {
+	type args struct {
+		layers []fs.FS
+		name   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOut string
+	}{
+		{
+			name: "parent in the root of a lower layer",
+			args: args{
+				layers: []fs.FS{
+					fstest.MapFS{"templates/x.stmpl": {Data: []byte("override {{ templateParent .Local }}")}},
+					fstest.MapFS{"x.stmpl": {Data: []byte("stock")}},
+				},
+				name: "x.stmpl",
+			},
+			wantOut: "override stock",
+		},
+		{
+			name: "parent in a search location of a lower layer",
+			args: args{
+				layers: []fs.FS{
+					fstest.MapFS{"x.stmpl": {Data: []byte("override {{ templateParent .Local }}")}},
+					fstest.MapFS{"templates/x.stmpl": {Data: []byte("stock")}},
+				},
+				name: "x.stmpl",
+			},
+			wantOut: "override stock",
+		},
+		{
+			name: "each layer in a different location",
+			args: args{
+				layers: []fs.FS{
+					fstest.MapFS{"templates/x.stmpl": {Data: []byte("override {{ templateParent .Local }}")}},
+					fstest.MapFS{"x.stmpl": {Data: []byte("theme {{ templateParent .Local }}")}},
+					fstest.MapFS{"templates/x.stmpl": {Data: []byte("stock")}},
+				},
+				name: "x.stmpl",
+			},
+			wantOut: "override theme stock",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(
+				easytemplate.WithSearchLocations([]string{"templates"}),
+				easytemplate.WithLayers(tt.args.layers...),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			out, err := e.TemplateString(context.Background(), tt.args.name, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package easytemplate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/speakeasy-api/easytemplate/internal/template"
+)
+
+// LintIssue describes a problem found in a template or script by Lint.
+type LintIssue struct {
+	// File is the template or script the issue was found in.
+	File string
+	// Line is the line number the issue was found on, or 0 if unknown.
+	Line int
+	// Message describes the issue.
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// Lint statically validates the provided templates and scripts without running them, the engine does not need to be initialized.
+// Paths ending in .js or .ts are treated as scripts, all others as templates. Directories are walked for .stmpl, .js and .ts files.
+//
+// Templates are parsed with the engine's registered template functions (plus any registered with registerTemplateFunc in the linted files),
+// sjs blocks and scripts are compiled, recurse is checked to only be used on the first line of a template and any templateFile/templateString
+// calls with literal paths are checked to be readable.
+// An error is only returned if the provided paths can't be read, problems found in the files are returned as issues.
+func (e *Engine) Lint(paths ...string) ([]LintIssue, error) {
+	files, err := e.lintFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]string, len(files))
+	extraFuncs := []string{}
+
+	for _, file := range files {
+		data, err := e.readFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		sources[file] = string(data)
+		extraFuncs = append(extraFuncs, template.DeclaredTemplateFuncs(string(data))...)
+	}
+
+	issues := []LintIssue{}
+
+	for _, file := range files {
+		var fileIssues []template.LintIssue
+		if isScript(file) {
+			fileIssues = e.templator.LintScript(file, sources[file])
+		} else {
+			fileIssues = e.templator.LintTemplate(file, sources[file], extraFuncs)
+		}
+
+		for _, issue := range fileIssues {
+			issues = append(issues, LintIssue{
+				File:    file,
+				Line:    issue.Line,
+				Message: issue.Message,
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
+
+// lintSource statically validates the provided contents of a template or script in the same way as Lint, without reading it from file,
+// for example to validate unsaved changes in an editor. Only template functions registered with registerTemplateFunc in the source itself
+// are known in addition to the engine's.
+func (e *Engine) lintSource(file, src string) []template.LintIssue {
+	var issues []template.LintIssue
+	if isScript(file) {
+		issues = e.templator.LintScript(file, src)
+	} else {
+		issues = e.templator.LintTemplate(file, src, template.DeclaredTemplateFuncs(src))
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues
+}
+
+func (e *Engine) lintFiles(paths []string) ([]string, error) {
+	files := []string{}
+
+	for _, p := range paths {
+		info, err := e.statFile(p)
+		if err != nil || !info.IsDir() {
+			// Let readFile resolve the path through the search locations
+			files = append(files, p)
+			continue
+		}
+
+		err = e.walkDir(p, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			if isScript(filePath) || path.Ext(filePath) == ".stmpl" {
+				files = append(files, filePath)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", p, err)
+		}
+	}
+
+	return files, nil
+}
+
+func isScript(file string) bool {
+	ext := strings.ToLower(path.Ext(file))
+	return ext == ".js" || ext == ".ts"
+}
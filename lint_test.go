@@ -0,0 +1,38 @@
+package easytemplate_test
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Lint(t *testing.T) {
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"templates/good.stmpl":    {Data: []byte("{{- recurse 1 -}}\n{{ templateString \"templates/partial.stmpl\" .Local }}\n{{ jsFunc .Local }}")},
+			"templates/partial.stmpl": {Data: []byte("{{ .Local }}")},
+			"templates/bad.stmpl":     {Data: []byte("{{ .Local }}\n{{ recurse 1 }}\n```sjs\nlet a = ;\nsjs```\n{{ unknownFunc }}\n{{ templateFile \"templates/missing.stmpl\" \"out.txt\" nil }}")},
+			"scripts/main.js":         {Data: []byte("registerTemplateFunc(\"jsFunc\", function(v) { return v; });\ntemplateFile(\"templates/other.stmpl\", \"out.txt\", {});\n")},
+		}),
+	)
+
+	issues, err := e.Lint("templates", "scripts/main.js")
+	require.NoError(t, err)
+
+	lines := make([]string, len(issues))
+	for i, issue := range issues {
+		lines[i] = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+	}
+
+	assert.Equal(t, []string{
+		"scripts/main.js:2",
+		"templates/bad.stmpl:2",
+		"templates/bad.stmpl:4",
+		"templates/bad.stmpl:6",
+		"templates/bad.stmpl:7",
+	}, lines)
+}
@@ -0,0 +1,251 @@
+package easytemplate
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFileMode fs.FileMode = 0o644
+	defaultDirMode  fs.FileMode = 0o755
+)
+
+// OutputFS is a file system the engine writes its output to, see WithOutputFS.
+// Names are slash separated paths as passed to templateFile.
+type OutputFS interface {
+	// WriteFile writes data to the named file, creating it if necessary and truncating it otherwise.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// MkdirAll creates the named directory along with any necessary parents.
+	MkdirAll(name string, perm fs.FileMode) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// Stat returns the FileInfo of the named file or directory.
+	Stat(name string) (fs.FileInfo, error)
+}
+
+// WithOutputFS sets the file system the output of templateFile (and files copied by TemplateDir) is written to, in place of the
+// default of writing to disk. Directories are created as needed before each file is written.
+//
+// Example:
+//
+//	out := easytemplate.NewMemoryFS()
+//	e := easytemplate.New(easytemplate.WithOutputFS(out))
+func WithOutputFS(out OutputFS) Opt {
+	return func(e *Engine) {
+		e.templator.WriteFunc = outputFSWriteFunc(out)
+		e.templator.StreamWriteFunc = nil
+		e.writesToDisk = false
+	}
+}
+
+func outputFSWriteFunc(out OutputFS) func(string, []byte) error {
+	return func(name string, data []byte) error {
+		if dir := path.Dir(filepath.ToSlash(name)); dir != "." {
+			if err := out.MkdirAll(dir, defaultDirMode); err != nil {
+				return err
+			}
+		}
+
+		return out.WriteFile(name, data, defaultFileMode)
+	}
+}
+
+// RunScriptToMap runs the script like RunScript, but instead of writing the output of each templateFile call it returns them
+// as a map of output file to contents. The engine's configured output is restored once the script completes.
+func (e *Engine) RunScriptToMap(ctx context.Context, scriptFile string) (map[string][]byte, error) {
+	if e.vm == nil {
+		return nil, ErrNotInitialized
+	}
+
+	out := NewMemoryFS()
+
+	writeFunc, streamWriteFunc, writesToDisk := e.templator.WriteFunc, e.templator.StreamWriteFunc, e.writesToDisk
+	e.templator.WriteFunc, e.templator.StreamWriteFunc, e.writesToDisk = outputFSWriteFunc(out), nil, false
+	defer func() {
+		e.templator.WriteFunc, e.templator.StreamWriteFunc, e.writesToDisk = writeFunc, streamWriteFunc, writesToDisk
+	}()
+
+	if err := e.RunScript(ctx, scriptFile); err != nil {
+		return nil, err
+	}
+
+	return out.Files(), nil
+}
+
+// NewOSFS returns an OutputFS writing to disk, with names relative to the root directory (or the working directory if root is empty).
+func NewOSFS(root string) OutputFS {
+	return osFS{root: root}
+}
+
+type osFS struct {
+	root string
+}
+
+func (o osFS) path(name string) string {
+	return filepath.Join(o.root, filepath.FromSlash(name))
+}
+
+func (o osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(o.path(name), data, perm)
+}
+
+func (o osFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(o.path(name), perm)
+}
+
+func (o osFS) Remove(name string) error {
+	return os.Remove(o.path(name))
+}
+
+func (o osFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(o.path(name))
+}
+
+// MemoryFS is an OutputFS holding the written files in memory, useful for tests and services that don't write to disk.
+// It is safe for concurrent use.
+type MemoryFS struct {
+	mu    sync.Mutex
+	files map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+var _ OutputFS = (*MemoryFS)(nil)
+
+// NewMemoryFS returns an empty MemoryFS.
+func NewMemoryFS() *MemoryFS {
+	return &MemoryFS{files: map[string]memoryEntry{}}
+}
+
+// WriteFile writes data to the named file.
+func (m *MemoryFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	name = cleanName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, ok := m.files[name]; ok && entry.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+
+	m.files[name] = memoryEntry{data: append([]byte(nil), data...), mode: perm.Perm(), modTime: time.Now()}
+
+	return nil
+}
+
+// MkdirAll creates the named directory along with any necessary parents.
+func (m *MemoryFS) MkdirAll(name string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for dir := cleanName(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if entry, ok := m.files[dir]; ok {
+			if !entry.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+			}
+			continue
+		}
+
+		m.files[dir] = memoryEntry{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+
+	return nil
+}
+
+// Remove removes the named file or empty directory.
+func (m *MemoryFS) Remove(name string) error {
+	name = cleanName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.files[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+
+	if entry.mode.IsDir() {
+		for other := range m.files {
+			if path.Dir(other) == name {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+			}
+		}
+	}
+
+	delete(m.files, name)
+
+	return nil
+}
+
+// Stat returns the FileInfo of the named file or directory.
+func (m *MemoryFS) Stat(name string) (fs.FileInfo, error) {
+	name = cleanName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return fileInfo{name: path.Base(name), size: int64(len(entry.data)), mode: entry.mode, modTime: entry.modTime}, nil
+}
+
+// ReadFile returns the contents of the named file.
+func (m *MemoryFS) ReadFile(name string) ([]byte, error) {
+	name = cleanName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.files[name]
+	if !ok || entry.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), entry.data...), nil
+}
+
+// Files returns a copy of the written files as a map of name to contents, excluding directories.
+func (m *MemoryFS) Files() map[string][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files := make(map[string][]byte, len(m.files))
+	for name, entry := range m.files {
+		if !entry.mode.IsDir() {
+			files[name] = append([]byte(nil), entry.data...)
+		}
+	}
+
+	return files
+}
+
+func cleanName(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// fileInfo is the fs.FileInfo of a file in one of the engine's output file systems.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (f fileInfo) Name() string       { return f.name }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() fs.FileMode  { return f.mode }
+func (f fileInfo) ModTime() time.Time { return f.modTime }
+func (f fileInfo) IsDir() bool        { return f.mode.IsDir() }
+func (f fileInfo) Sys() any           { return nil }
@@ -0,0 +1,48 @@
+package easytemplate_test
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_OutputFS(t *testing.T) {
+	readFS := fstest.MapFS{
+		"main.js":    {Data: []byte("templateFile(\"tmpl.stmpl\", \"out/a.txt\", {name: \"a\"});\ntemplateFile(\"tmpl.stmpl\", \"out/nested/b.txt\", {name: \"b\"});\n")},
+		"tmpl.stmpl": {Data: []byte("hello {{ .Local.name }}")},
+	}
+
+	out := easytemplate.NewMemoryFS()
+
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(readFS),
+		easytemplate.WithOutputFS(out),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"out/a.txt": []byte("hello a"), "out/nested/b.txt": []byte("hello b")}, out.Files())
+
+	info, err := out.Stat("out/nested")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	require.NoError(t, out.Remove("out/a.txt"))
+	_, err = out.Stat("out/a.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	files, err := e.RunScriptToMap(context.Background(), "main.js")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"out/a.txt": []byte("hello a"), "out/nested/b.txt": []byte("hello b")}, files)
+
+	// The configured output is restored after RunScriptToMap
+	assert.Len(t, out.Files(), 1)
+}
@@ -0,0 +1,70 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateFile_OutputModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		outFile  string
+		wantOut  string
+	}{
+		{
+			name:     "html extension is escaped",
+			template: "page.stmpl",
+			outFile:  "page.html",
+			wantOut:  "<p>&lt;a &amp; &#34;b&#34;&gt;</p>\n<b>&lt;a &amp; &#34;b&#34;&gt;</b>",
+		},
+		{
+			name:     "other extensions aren't escaped",
+			template: "page.stmpl",
+			outFile:  "page.txt",
+			wantOut:  "<p><a & \"b\"></p>\n<b><a & \"b\"></b>",
+		},
+		{
+			name:     "pragma sets html mode",
+			template: "pragma.stmpl",
+			outFile:  "pragma.txt",
+			wantOut:  "<p>&lt;a &amp; &#34;b&#34;&gt;</p>",
+		},
+		{
+			name:     "escape functions",
+			template: "escape.stmpl",
+			outFile:  "escape.txt",
+			wantOut:  "{\"name\": \"<a & \\\"b\\\">\"}\n<name>&lt;a &amp; &#34;b&#34;&gt;</name>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := map[string]string{}
+
+			e := easytemplate.New(
+				easytemplate.WithOutputModeForExt(easytemplate.OutputHTML, ".html"),
+				easytemplate.WithReadFileSystem(fstest.MapFS{
+					"page.stmpl":   {Data: []byte("<p>{{ .Local.name }}</p>\n```sjs\nrender(\"<b>{{ .Local.name }}</b>\");\nsjs```")},
+					"pragma.stmpl": {Data: []byte("#easytemplate mode=html\n<p>{{ .Local.name }}</p>")},
+					"escape.stmpl": {Data: []byte("{\"name\": \"{{ escapeJSON .Local.name }}\"}\n<name>{{ escapeXML .Local.name }}</name>")},
+				}),
+				easytemplate.WithWriteFunc(func(s string, b []byte) error {
+					written[s] = string(b)
+					return nil
+				}),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			err = e.TemplateFile(context.Background(), tt.template, tt.outFile, map[string]any{"name": `<a & "b">`})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, written[tt.outFile])
+		})
+	}
+}
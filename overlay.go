@@ -0,0 +1,123 @@
+package easytemplate
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// OSLayer is a layer (see WithLayers) reading files from disk, the same way files are read when no read file system is set.
+// Unlike os.DirFS, paths are resolved relative to the working directory and may be absolute or start with ../
+var OSLayer fs.FS = osLayer{}
+
+// osLayer reads files from disk without the path validation fs.FS implementations require, as the OS does when layers aren't used.
+type osLayer struct{}
+
+var (
+	_ fs.StatFS     = osLayer{}
+	_ fs.ReadDirFS  = osLayer{}
+	_ fs.ReadFileFS = osLayer{}
+)
+
+func (osLayer) Open(name string) (fs.File, error) {
+	return os.Open(filepath.FromSlash(name))
+}
+
+func (osLayer) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(filepath.FromSlash(name))
+}
+
+func (osLayer) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.FromSlash(name))
+}
+
+func (osLayer) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(filepath.FromSlash(name))
+}
+
+// inLayer returns true if the path can be looked up in the layer, only the OS layer can read paths that aren't valid fs.FS paths
+// (ie absolute paths or those starting with ../), which don't exist in any other layer.
+func inLayer(layer fs.FS, name string) bool {
+	_, isOS := layer.(osLayer)
+	return isOS || fs.ValidPath(name)
+}
+
+// overlayFS is the read file system of an engine using layers (see WithLayers). Files in earlier layers shadow those at the
+// same path in later ones, and directory listings are merged across all the layers.
+type overlayFS struct {
+	layers []fs.FS
+}
+
+var (
+	_ fs.StatFS     = overlayFS{}
+	_ fs.ReadDirFS  = overlayFS{}
+	_ fs.ReadFileFS = overlayFS{}
+)
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	i, err := layerOf(o.layers, name, 0, "open")
+	if err != nil {
+		return nil, err
+	}
+
+	return o.layers[i].Open(name)
+}
+
+func (o overlayFS) Stat(name string) (fs.FileInfo, error) {
+	i, err := layerOf(o.layers, name, 0, "stat")
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.Stat(o.layers[i], name)
+}
+
+func (o overlayFS) ReadFile(name string) ([]byte, error) {
+	i, err := layerOf(o.layers, name, 0, "read")
+	if err != nil {
+		return nil, err
+	}
+
+	return fs.ReadFile(o.layers[i], name)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := map[string]fs.DirEntry{}
+	found := false
+
+	for _, layer := range o.layers {
+		if !inLayer(layer, name) {
+			continue
+		}
+
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		found = true
+
+		for _, entry := range layerEntries {
+			if _, ok := entries[entry.Name()]; !ok {
+				entries[entry.Name()] = entry
+			}
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	out := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+
+	return out, nil
+}
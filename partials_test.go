@@ -0,0 +1,128 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateString_PartialsAndLayouts(t *testing.T) {
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithPartials("partials"),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"templates/partials/header.stmpl":       {Data: []byte("// Code generated for {{ .Local.name }}.")},
+			"templates/partials/components/x.stmpl": {Data: []byte(`{{ define "footer" }}// end{{ end }}`)},
+			"templates/layouts/base.stmpl":          {Data: []byte("{{ template \"header\" . }}\n{{ block \"body\" . }}default body{{ end }}\n{{ template \"footer\" . }}")},
+			"templates/layouts/child.stmpl":         {Data: []byte("{{ extends \"layouts/base.stmpl\" }}{{ define \"body\" }}child: {{ block \"inner\" . }}inner{{ end }}{{ end }}")},
+			"templates/page.stmpl":                  {Data: []byte("{{ extends \"layouts/base.stmpl\" }}\n{{ define \"body\" }}page {{ .Local.name }}{{ end }}")},
+			"templates/nested.stmpl":                {Data: []byte("{{ extends \"layouts/child.stmpl\" }}\n{{ define \"inner\" }}nested{{ end }}")},
+			"templates/default.stmpl":               {Data: []byte("{{ extends \"layouts/base.stmpl\" }}")},
+			"templates/override.stmpl":              {Data: []byte("{{ define \"header\" }}overridden{{ end }}{{ template \"header\" . }}")},
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	data := map[string]any{"name": "test"}
+
+	out, err := e.TemplateString(context.Background(), "page.stmpl", data)
+	require.NoError(t, err)
+	assert.Equal(t, "// Code generated for test.\npage test\n// end", out)
+
+	out, err = e.TemplateString(context.Background(), "nested.stmpl", data)
+	require.NoError(t, err)
+	assert.Equal(t, "// Code generated for test.\nchild: nested\n// end", out)
+
+	out, err = e.TemplateString(context.Background(), "default.stmpl", data)
+	require.NoError(t, err)
+	assert.Equal(t, "// Code generated for test.\ndefault body\n// end", out)
+
+	out, err = e.TemplateString(context.Background(), "override.stmpl", data)
+	require.NoError(t, err)
+	assert.Equal(t, "overridden", out)
+
+	// Blocks defined by other templates don't override the partials
+	out, err = e.TemplateString(context.Background(), "page.stmpl", data)
+	require.NoError(t, err)
+	assert.Equal(t, "// Code generated for test.\npage test\n// end", out)
+}
+
+func TestEngine_TemplateString_LayoutDelimiters(t *testing.T) {
+	type args struct {
+		templates fstest.MapFS
+		opts      []easytemplate.Opt
+		localName string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOut string
+	}{
+		{
+			name: "layout with pragma extended by template with default delimiters",
+			args: args{
+				templates: fstest.MapFS{
+					"layout.stmpl": {Data: []byte("#easytemplate delims=[[,]]\n{{ literal }} [[ block \"body\" . ]]default[[ end ]]")},
+					"page.stmpl":   {Data: []byte("{{ extends \"layout.stmpl\" }}{{ define \"body\" }}page {{ .Local.name }}{{ end }}")},
+				},
+			},
+			wantOut: "{{ literal }} page test",
+		},
+		{
+			name: "template with pragma extending layout with default delimiters",
+			args: args{
+				templates: fstest.MapFS{
+					"layout.stmpl": {Data: []byte("[[ literal ]] {{ block \"body\" . }}default{{ end }}")},
+					"page.stmpl":   {Data: []byte("#easytemplate delims=<<,>>\n<< extends \"layout.stmpl\" >><< define \"body\" >>page << .Local.name >><< end >>")},
+				},
+			},
+			wantOut: "[[ literal ]] page test",
+		},
+		{
+			name: "nested layouts each with their own delimiters",
+			args: args{
+				templates: fstest.MapFS{
+					"base.stmpl":  {Data: []byte("#easytemplate delims=[[,]]\nbase: [[ block \"body\" . ]]default[[ end ]]")},
+					"child.stmpl": {Data: []byte("#easytemplate delims=<<,>>\n<< extends \"base.stmpl\" >><< define \"body\" >>child: << block \"inner\" . >>inner<< end >><< end >>")},
+					"page.stmpl":  {Data: []byte("{{ extends \"child.stmpl\" }}{{ define \"inner\" }}{{ .Local.name }}{{ end }}")},
+				},
+			},
+			wantOut: "base: child: test",
+		},
+		{
+			name: "layout with pragma in html mode",
+			args: args{
+				templates: fstest.MapFS{
+					"layout.stmpl": {Data: []byte("#easytemplate delims=[[,]]\n<p>[[ block \"body\" . ]]default[[ end ]]</p>")},
+					"page.stmpl":   {Data: []byte("{{ extends \"layout.stmpl\" }}{{ define \"body\" }}{{ .Local.name }}{{ end }}")},
+				},
+				opts:      []easytemplate.Opt{easytemplate.WithOutputMode(easytemplate.OutputHTML)},
+				localName: "<b>",
+			},
+			wantOut: "<p>&lt;b&gt;</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(append([]easytemplate.Opt{easytemplate.WithReadFileSystem(tt.args.templates)}, tt.args.opts...)...)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			name := tt.args.localName
+			if name == "" {
+				name = "test"
+			}
+
+			out, err := e.TemplateString(context.Background(), "page.stmpl", map[string]any{"name": name})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Package sprig provides the Sprig template functions (https://masterminds.github.io/sprig/) for use with easytemplate,
+// for templates written for Helm-style ecosystems that expect them.
+//
+// The functions can be installed as template functions only:
+//
+//	e := easytemplate.New(easytemplate.WithTemplateFuncs(sprig.FuncMap()))
+//
+// or as both template functions and a js function named sprig, called with the name of the Sprig function followed by
+// its arguments (ie sprig("toJson", obj)):
+//
+//	e := easytemplate.New(sprig.WithSprig())
+package sprig
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate"
+)
+
+// FuncMap returns Sprig's hermetic template functions, excluding any that would clash with the functions reserved by the engine.
+// Functions whose output isn't repeatable or that expose the host, such as env, expandenv, now, randAlpha, uuidv4 and getHostByName,
+// aren't included, so templates can't read the environment of the process rendering them.
+// A new map is returned on each call so it can be safely modified.
+func FuncMap() map[string]any {
+	funcs := sprig.HermeticTxtFuncMap()
+
+	for name := range funcs {
+		if easytemplate.IsReserved(name) {
+			delete(funcs, name)
+		}
+	}
+
+	return funcs
+}
+
+// WithSprig installs the Sprig functions as template functions and exposes them to js through a function named sprig,
+// called with the name of the Sprig function followed by its arguments, as some of their names (ie default) are reserved words in js.
+func WithSprig() easytemplate.Opt {
+	return func(e *easytemplate.Engine) {
+		funcs := FuncMap()
+
+		easytemplate.WithTemplateFuncs(FuncMap())(e)
+		easytemplate.WithJSFuncs(map[string]func(call easytemplate.CallContext) goja.Value{
+			"sprig": func(call easytemplate.CallContext) goja.Value {
+				name := call.Argument(0).String()
+
+				fn, ok := funcs[name]
+				if !ok {
+					panic(call.VM.NewGoError(fmt.Errorf("%w: unknown sprig function %s", easytemplate.ErrInvalidArg, name)))
+				}
+
+				// Let goja convert the arguments and results as it does for any other Go function
+				callable, _ := goja.AssertFunction(call.VM.ToValue(fn))
+
+				var args []goja.Value
+				if len(call.Arguments) > 1 {
+					args = call.Arguments[1:]
+				}
+
+				res, err := callable(goja.Undefined(), args...)
+				if err != nil {
+					panic(err)
+				}
+
+				return res
+			},
+		})(e)
+	}
+}
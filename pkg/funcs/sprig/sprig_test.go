@@ -0,0 +1,51 @@
+package sprig_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/speakeasy-api/easytemplate/pkg/funcs/sprig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSprig(t *testing.T) {
+	e := easytemplate.New(sprig.WithSprig())
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	out, err := e.TemplateStringInput(context.Background(), "test", `{{ default "anon" .Local.name }} {{ dict "a" 1 | toJson }} {{ list 1 2 | len }} {{ b64enc "hi" }} {{ (semver "1.2.3").Minor }}`, map[string]any{})
+	require.NoError(t, err)
+	assert.Equal(t, `anon {"a":1} 2 aGk= 2`, out)
+
+	out, err = e.TemplateStringInput(context.Background(), "test", "```sjs\nrender(sprig(\"default\", \"anon\", \"\") + \" \" + sprig(\"toJson\", sprig(\"list\", 1, \"a\")) + \" \" + sprig(\"b64enc\", \"hi\"));\nsjs```", nil)
+	require.NoError(t, err)
+	assert.Equal(t, `anon [1,"a"] aGk=`, out)
+}
+
+func TestWithSprig_UnknownFunction(t *testing.T) {
+	e := easytemplate.New(sprig.WithSprig())
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = e.TemplateStringInput(context.Background(), "test", "```sjs\nrender(sprig(\"missing\"));\nsjs```", nil)
+	assert.ErrorContains(t, err, "unknown sprig function missing")
+}
+
+func TestFuncMap_DoesNotClashWithReserved(t *testing.T) {
+	assert.NotPanics(t, func() {
+		easytemplate.New(easytemplate.WithTemplateFuncs(sprig.FuncMap()))
+	})
+}
+
+func TestFuncMap_IsHermetic(t *testing.T) {
+	funcs := sprig.FuncMap()
+
+	for _, name := range []string{"env", "expandenv", "now", "date", "randAlpha", "uuidv4", "getHostByName"} {
+		assert.NotContains(t, funcs, name)
+	}
+	assert.Contains(t, funcs, "toJson")
+}
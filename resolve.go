@@ -0,0 +1,314 @@
+package easytemplate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxSuggestions is the maximum number of similarly named files suggested when a file isn't found.
+const maxSuggestions = 3
+
+// NotFoundError is returned when a template, script or other file can't be found. It lists every path that was tried
+// and any similarly named files that exist, and matches both ErrTemplateNotFound and fs.ErrNotExist with errors.Is.
+type NotFoundError struct {
+	// Name is the path of the file as requested.
+	Name string
+	// Tried lists every candidate path that was tried, in order.
+	Tried []string
+
+	// suggest finds the suggestions, only once they're asked for as most files that aren't found are never reported
+	suggest     func() []string
+	suggestOnce sync.Once
+	suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("%s: %s, tried: %s", e.Name, ErrTemplateNotFound.Error(), strings.Join(e.Tried, ", "))
+
+	if suggestions := e.Suggestions(); len(suggestions) > 0 {
+		msg += fmt.Sprintf("; did you mean %s?", strings.Join(suggestions, " or "))
+	}
+
+	return msg
+}
+
+// Suggestions returns existing files with names similar to the requested file.
+func (e *NotFoundError) Suggestions() []string {
+	e.suggestOnce.Do(func() {
+		if e.suggest != nil {
+			e.suggestions = e.suggest()
+		}
+	})
+
+	return e.suggestions
+}
+
+// Is reports whether the target is ErrTemplateNotFound or fs.ErrNotExist.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrTemplateNotFound || target == fs.ErrNotExist
+}
+
+// WithPathAlias registers an alias for a directory, so files within it can be referenced as alias/path from anywhere, ie with
+// WithPathAlias("@pack", "vendor/templates/pack") a template can call {{ templateString "@pack/header.stmpl" . }}.
+// Aliases apply to all files read by the engine and the directory is resolved through the search locations like any other path.
+func WithPathAlias(alias, dir string) Opt {
+	return func(e *Engine) {
+		if e.pathAliases == nil {
+			e.pathAliases = map[string]string{}
+		}
+
+		e.pathAliases[strings.TrimSuffix(alias, "/")] = dir
+	}
+}
+
+// locate returns the path the file referenced by ref is read from, when referenced by templateFile, templateString or similar from the
+// template or script from (which may be empty). Relative references, path aliases and the search locations are resolved the same way
+// as when rendering, and if the file isn't found a *NotFoundError is returned.
+func (e *Engine) locate(ref, from string) (string, error) {
+	return e.findFile(e.resolveRef(ref, from))
+}
+
+// resolveRef resolves a reference to a file made from the template or script from. References starting with ./ or ../
+// are relative to the directory from resides in, all others are returned unchanged to be resolved through any alias and the search locations.
+func (e *Engine) resolveRef(ref, from string) string {
+	if !strings.HasPrefix(ref, "./") && !strings.HasPrefix(ref, "../") {
+		return ref
+	}
+
+	if from == "" {
+		return path.Clean(ref)
+	}
+
+	return path.Join(path.Dir(e.resolvePath(from)), ref)
+}
+
+// resolveLoaderRef resolves a reference made to one of the file or data loaders (ie readJSON or fs.readFile), which don't know their caller.
+// References starting with ./ or ../ are resolved relative to the template currently being rendered if the file exists there,
+// otherwise they're resolved through the search locations like any other path.
+func (e *Engine) resolveLoaderRef(ref string) string {
+	resolved := e.resolveRef(ref, e.templator.CurrentTemplate())
+	if resolved == ref {
+		return ref
+	}
+
+	if _, err := e.findFile(resolved); err != nil {
+		return ref
+	}
+
+	return resolved
+}
+
+// expandAlias replaces a leading path alias with the directory it refers to.
+func (e *Engine) expandAlias(file string) string {
+	alias, rest, _ := strings.Cut(file, "/")
+
+	dir, ok := e.pathAliases[alias]
+	if !ok {
+		return file
+	}
+
+	return path.Join(dir, rest)
+}
+
+func (e *Engine) readFile(file string) ([]byte, error) {
+	return e.readFileFrom(file)
+}
+
+// readFileFrom reads the file, resolving it through the search locations and falling back to resolving it relative to each of the
+// provided directories (ie the directory of the calling script).
+func (e *Engine) readFileFrom(file string, relativeTo ...string) ([]byte, error) {
+	filePath, err := e.findFile(file, relativeTo...)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent := e.parentRead; parent != nil && parent.path == filePath {
+		e.parentRead = nil
+		return fs.ReadFile(e.layers[parent.layer], parent.file)
+	}
+
+	if e.readFS != nil {
+		return fs.ReadFile(e.readFS, filePath)
+	}
+	return os.ReadFile(filePath)
+}
+
+// resolvePath returns the path of the file or directory in the first search location it exists in, or the path unchanged if it isn't found.
+func (e *Engine) resolvePath(file string) string {
+	filePath, err := e.findFile(file)
+	if err != nil {
+		return file
+	}
+
+	return filePath
+}
+
+// findFile returns the path of the file or directory, searching each read file system in order and within each the search locations
+// and the path itself, followed by the same for the path relative to each of the provided directories. If it isn't found a *NotFoundError is returned.
+func (e *Engine) findFile(file string, relativeTo ...string) (string, error) {
+	requested := file
+	file = e.expandAlias(file)
+
+	candidates := e.searchPaths(file)
+	for _, dir := range relativeTo {
+		if dir != "" && dir != "." {
+			candidates = append(candidates, e.searchPaths(path.Join(dir, file))...)
+		}
+	}
+
+	sources := e.readSources()
+	tried := make([]string, 0, len(sources)*len(candidates))
+
+	for i, source := range sources {
+		for _, candidate := range candidates {
+			if _, err := statIn(source, candidate); err == nil {
+				return candidate, nil
+			}
+
+			if len(sources) > 1 {
+				tried = append(tried, fmt.Sprintf("%s (file system %d)", candidate, i))
+			} else {
+				tried = append(tried, candidate)
+			}
+		}
+	}
+
+	return "", &NotFoundError{
+		Name:    requested,
+		Tried:   tried,
+		suggest: func() []string { return e.suggestFiles(sources, candidates) },
+	}
+}
+
+// searchPaths returns the path within each of the search locations, followed by the path itself.
+func (e *Engine) searchPaths(file string) []string {
+	paths := make([]string, 0, len(e.searchLocations)+1)
+	for _, dir := range e.searchLocations {
+		paths = append(paths, path.Join(dir, file))
+	}
+
+	return append(paths, file)
+}
+
+// readSources returns the file systems files are read from in priority order, a nil file system represents the OS.
+func (e *Engine) readSources() []fs.FS {
+	if overlay, ok := e.readFS.(overlayFS); ok {
+		return overlay.layers
+	}
+
+	return []fs.FS{e.readFS}
+}
+
+func statIn(source fs.FS, filePath string) (fs.FileInfo, error) {
+	if source != nil {
+		return fs.Stat(source, filePath)
+	}
+	return os.Stat(filePath)
+}
+
+func readDirIn(source fs.FS, dir string) ([]fs.DirEntry, error) {
+	if source != nil {
+		return fs.ReadDir(source, dir)
+	}
+	return os.ReadDir(dir)
+}
+
+// suggestFiles returns the existing files most similarly named to the candidates, from the directories the candidates would be in.
+func (e *Engine) suggestFiles(sources []fs.FS, candidates []string) []string {
+	type suggestion struct {
+		path     string
+		distance int
+	}
+
+	seen := map[string]struct{}{}
+	suggestions := []suggestion{}
+
+	for _, source := range sources {
+		for _, candidate := range candidates {
+			dir, base := path.Split(candidate)
+			dir = path.Clean(dir)
+
+			entries, err := readDirIn(source, dir)
+			if err != nil {
+				continue
+			}
+
+			for _, entry := range entries {
+				name := entry.Name()
+				distance := levenshtein(strings.ToLower(base), strings.ToLower(name))
+				if distance == 0 || distance > maxSuggestionDistance(base) {
+					continue
+				}
+
+				suggested := path.Join(dir, name)
+				if _, ok := seen[suggested]; ok {
+					continue
+				}
+				seen[suggested] = struct{}{}
+
+				suggestions = append(suggestions, suggestion{path: suggested, distance: distance})
+			}
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].distance < suggestions[j].distance })
+
+	out := []string{}
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		out = append(out, suggestions[i].path)
+	}
+
+	return out
+}
+
+// maxSuggestionDistance returns the maximum edit distance for a file name to be suggested, scaled by the length of the name.
+func maxSuggestionDistance(name string) int {
+	const minDistance, ratio = 2, 4
+
+	if d := len(name) / ratio; d > minDistance {
+		return d
+	}
+
+	return minDistance
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,97 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_NotFoundError(t *testing.T) {
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"scripts/main.js":        {Data: []byte("require(\"./helpers.js\");\n")},
+			"scripts/helper.js":      {Data: []byte("")},
+			"templates/model.stmpl":  {Data: []byte("model")},
+			"templates/models.stmpl": {Data: []byte("models")},
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	_, err = e.TemplateString(context.Background(), "modle.stmpl", nil)
+	require.ErrorIs(t, err, easytemplate.ErrTemplateNotFound)
+
+	var notFound *easytemplate.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "modle.stmpl", notFound.Name)
+	assert.Equal(t, []string{"templates/modle.stmpl", "modle.stmpl"}, notFound.Tried)
+	assert.Equal(t, []string{"templates/model.stmpl", "templates/models.stmpl"}, notFound.Suggestions())
+	assert.ErrorContains(t, err, "did you mean templates/model.stmpl or templates/models.stmpl?")
+
+	err = e.RunScript(context.Background(), "scripts/main.js")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "tried: templates/helpers.js, ./helpers.js, templates/scripts/helpers.js, scripts/helpers.js; did you mean scripts/helper.js?")
+}
+
+func TestEngine_RunScript_RelativePathsFallBackToSearchLocations(t *testing.T) {
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"scripts/main.js":             {Data: []byte("require(\"./helpers.js\");\n")},
+			"templates/helpers.js":        {Data: []byte("var helperName = \"helpers\";\n")},
+			"templates/data.json":         {Data: []byte(`{"name": "search"}`)},
+			"templates/models/user.stmpl": {Data: []byte(`{{ (readJSON "./data.json").name }} {{ (readJSON "./shared.json").name }}`)},
+			"templates/models/data.json":  {Data: []byte(`{"name": "relative"}`)},
+			"templates/shared.json":       {Data: []byte(`{"name": "shared"}`)},
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "scripts/main.js")
+	require.NoError(t, err)
+
+	out, err := e.TemplateString(context.Background(), "models/user.stmpl", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "relative shared", out)
+}
+
+func TestEngine_RunScript_RelativePaths(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithPathAlias("@pack", "vendor/pack"),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"scripts/main.js":                {Data: []byte("require(\"./lib/helpers.js\");\ntemplateFile(\"../templates/models/user.stmpl\", \"user.txt\", {name: name()});\n")},
+			"scripts/lib/helpers.js":         {Data: []byte("function name() { return \"user\"; }\n")},
+			"templates/models/user.stmpl":    {Data: []byte("{{ templateString \"./_header.stmpl\" .Local }} {{ templateString \"../shared/footer.stmpl\" .Local }} {{ templateString \"@pack/badge.stmpl\" .Local }}")},
+			"templates/models/_header.stmpl": {Data: []byte("header {{ .Local.name }}\n```sjs\nrender(templateString(\"./_title.stmpl\", {}));\nsjs```")},
+			"templates/models/_title.stmpl":  {Data: []byte("title")},
+			"templates/shared/footer.stmpl":  {Data: []byte("footer")},
+			"vendor/pack/badge.stmpl":        {Data: []byte("badge")},
+		}),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "scripts/main.js")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"user.txt": "header user\ntitle footer badge"}, written)
+
+	issues, err := e.Lint("templates")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
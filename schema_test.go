@@ -0,0 +1,54 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateString_SchemaValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    any
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "valid data",
+			data:    map[string]any{"name": "John"},
+			wantOut: "Hello John",
+		},
+		{
+			name:    "missing required property",
+			data:    map[string]any{"nmae": "John"},
+			wantErr: "invalid data for template templates/hello.stmpl: schema validation failed: .Local.name: required property is missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(
+				easytemplate.WithSchemaValidation(),
+				easytemplate.WithReadFileSystem(fstest.MapFS{
+					"templates/hello.stmpl":       {Data: []byte("Hello {{ .Local.name }}")},
+					"templates/hello.schema.json": {Data: []byte(`{"type": "object", "required": ["name"], "additionalProperties": false, "properties": {"name": {"type": "string"}}}`)},
+				}),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			out, err := e.TemplateString(context.Background(), "templates/hello.stmpl", tt.data)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, easytemplate.ErrSchemaValidation)
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RunScript_Stdlib(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithStdlib(),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"main.js":     {Data: []byte("templateFile(\"model.stmpl\", pascalCase(\"user_account\") + \".txt\", {name: \"user_account\", js: [camelCase(\"user_account\"), pluralize(\"category\"), goIdentifier(\"type\")]});\n")},
+			"model.stmpl": {Data: []byte("{{ camelCase .Local.name }} {{ pluralize \"category\" }} {{ goIdentifier \"type\" }}\n{{ range .Local.js }}{{ . }} {{ end }}")},
+		}),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"UserAccount.txt": "userAccount categories type_\nuserAccount categories type_ "}, written)
+}
@@ -0,0 +1,155 @@
+package easytemplate_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingWriter records each write made to it, and whether it has been closed.
+type recordingWriter struct {
+	writes []string
+	closed bool
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func (w *recordingWriter) String() string {
+	return strings.Join(w.writes, "")
+}
+
+func TestEngine_TemplateTo(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     string
+		wantOut      string
+		wantStreamed bool
+	}{
+		{
+			name:     "executed directly into the writer",
+			template: "hello.stmpl",
+			wantOut:  "\nhello John!",
+			// The template is executed directly into the writer, rather than written once rendered
+			wantStreamed: true,
+		},
+		{
+			name:     "recursive template written once rendered",
+			template: "recurse.stmpl",
+			wantOut:  "\nJohn John",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(easytemplate.WithReadFileSystem(fstest.MapFS{
+				"hello.stmpl":   {Data: []byte("```sjs\ncontext.LocalComputed.greeting = \"hello\";\nsjs```\n{{ .LocalComputed.greeting }} {{ .Local.name }}!")},
+				"recurse.stmpl": {Data: []byte("{{ recurse 1 }}\n{{ .Local.name }} {{ \"{{ .Local.name }}\" }}")},
+			}))
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			w := &recordingWriter{}
+			err = e.TemplateTo(context.Background(), w, tt.template, map[string]any{"name": "John"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, w.String())
+			if tt.wantStreamed {
+				assert.Greater(t, len(w.writes), 1)
+			}
+		})
+	}
+}
+
+func TestEngine_TemplateFile_StreamWriteFunc(t *testing.T) {
+	readFS := fstest.MapFS{
+		"hello.stmpl": {Data: []byte("hello {{ .Local.name }}")},
+		"data.stmpl":  {Data: []byte(`{"name":"{{ .Local.name }}"}`)},
+	}
+
+	opened := map[string]*recordingWriter{}
+
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(readFS),
+		easytemplate.WithFormatter(".json", easytemplate.JSONFormatter()),
+		easytemplate.WithStreamWriteFunc(func(name string) (io.WriteCloser, error) {
+			w := &recordingWriter{}
+			opened[name] = w
+			return w, nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateFile(context.Background(), "hello.stmpl", "out/hello.txt", map[string]any{"name": "John"})
+	require.NoError(t, err)
+	require.Contains(t, opened, "out/hello.txt")
+	assert.Equal(t, "hello John", opened["out/hello.txt"].String())
+	assert.Greater(t, len(opened["out/hello.txt"].writes), 1)
+	assert.True(t, opened["out/hello.txt"].closed)
+
+	// Formatted output is buffered, then written in one go
+	err = e.TemplateFile(context.Background(), "data.stmpl", "out/data.json", map[string]any{"name": "John"})
+	require.NoError(t, err)
+	require.Contains(t, opened, "out/data.json")
+	assert.Equal(t, []string{"{\n  \"name\": \"John\"\n}\n"}, opened["out/data.json"].writes)
+	assert.True(t, opened["out/data.json"].closed)
+
+	// Without front-matter an empty output file is passed through as it always has been
+	err = e.TemplateFile(context.Background(), "hello.stmpl", "", map[string]any{"name": "John"})
+	require.NoError(t, err)
+	require.Contains(t, opened, "")
+	assert.Equal(t, "hello John", opened[""].String())
+}
+
+type abortingWriter struct {
+	recordingWriter
+	abortErr error
+}
+
+func (w *abortingWriter) CloseWithError(err error) error {
+	w.abortErr = err
+	return nil
+}
+
+var _ easytemplate.WriteAborter = &abortingWriter{}
+
+func TestEngine_TemplateFile_StreamWriteFuncAbort(t *testing.T) {
+	readFS := fstest.MapFS{
+		"broken.stmpl": {Data: []byte(`hello {{ .Local.name }} {{ index .Local.list 5 }}`)},
+	}
+
+	opened := map[string]*abortingWriter{}
+
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(readFS),
+		easytemplate.WithStreamWriteFunc(func(name string) (io.WriteCloser, error) {
+			w := &abortingWriter{}
+			opened[name] = w
+			return w, nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateFile(context.Background(), "broken.stmpl", "out/broken.txt", map[string]any{"name": "John", "list": []string{}})
+	require.Error(t, err)
+	require.Contains(t, opened, "out/broken.txt")
+	assert.Equal(t, "hello John ", opened["out/broken.txt"].String())
+	assert.False(t, opened["out/broken.txt"].closed)
+	assert.ErrorContains(t, opened["out/broken.txt"].abortErr, "index out of range")
+}
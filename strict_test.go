@@ -0,0 +1,64 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateString_StrictMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     any
+		wantErr  string
+	}{
+		{
+			name:     "renders when all values are present",
+			template: "{{ .Local.name }} {{ defined }}\n```sjs\nrender(context.Local.name);\nsjs```",
+			data:     map[string]any{"name": "John"},
+		},
+		{
+			name:     "fails on missing key",
+			template: "{{ .Local.nmae }}",
+			data:     map[string]any{"name": "John"},
+			wantErr:  `map has no entry for key "nmae"`,
+		},
+		{
+			name:     "fails on rendering undefined",
+			template: "```sjs\nrender(context.Local.nmae);\nsjs```",
+			data:     map[string]any{"name": "John"},
+			wantErr:  "undefined value: render called with undefined",
+		},
+		{
+			name:     "fails on template function returning undefined",
+			template: "{{ undefinedFunc }}",
+			wantErr:  "undefined value: template function undefinedFunc returned undefined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := easytemplate.New(easytemplate.WithStrictMode())
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			_, err = e.TemplateStringInput(context.Background(), "funcs", "```sjs\n"+
+				"registerTemplateFunc(\"defined\", function() { return \"defined\"; });\n"+
+				"registerTemplateFunc(\"undefinedFunc\", function() { return undefined; });\n"+
+				"sjs```", nil)
+			require.NoError(t, err)
+
+			_, err = e.TemplateStringInput(context.Background(), "test", tt.template, tt.data)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
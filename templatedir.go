@@ -0,0 +1,250 @@
+package easytemplate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/speakeasy-api/easytemplate/internal/utils"
+	"github.com/speakeasy-api/easytemplate/internal/vm"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const templateExt = ".stmpl"
+
+// TemplateDirOpt configures how TemplateDir renders a directory.
+type TemplateDirOpt func(*templateDirOptions)
+
+type templateDirOptions struct {
+	include []string
+	exclude []string
+}
+
+// WithInclude limits TemplateDir to files whose path relative to the source directory matches one of the provided globs.
+// Globs use path.Match syntax with the addition of ** matching any number of directories.
+func WithInclude(globs ...string) TemplateDirOpt {
+	return func(o *templateDirOptions) {
+		o.include = append(o.include, globs...)
+	}
+}
+
+// WithExclude skips files and directories whose path relative to the source directory matches one of the provided globs.
+// Globs use path.Match syntax with the addition of ** matching any number of directories.
+func WithExclude(globs ...string) TemplateDirOpt {
+	return func(o *templateDirOptions) {
+		o.exclude = append(o.exclude, globs...)
+	}
+}
+
+// TemplateDir renders the directory tree at srcDir into outDir, with the provided data available as .Local.
+//
+// Files ending in .stmpl are rendered as templates and written without the .stmpl extension, all other files are copied verbatim.
+// File and directory names may contain template actions (ie {{ .Local.pkg }}/client.go.stmpl) which are rendered with the same data,
+// any file or directory whose name renders to an empty string is skipped, as are templates disabled by their front-matter. A rendered
+// name must be a single file or directory name, names such as .. or containing / or \ return an error naming the entry.
+func (e *Engine) TemplateDir(ctx context.Context, srcDir, outDir string, data any, opts ...TemplateDirOpt) error {
+	if e.vm == nil {
+		return ErrNotInitialized
+	}
+
+	flush := e.collectErrors()
+
+	return flush(e.templateDir(ctx, e.vm, srcDir, outDir, data, opts...))
+}
+
+func (e *Engine) templateDirJS(call CallContext) goja.Value {
+	srcDir := e.resolveRef(call.Argument(0).String(), callerFile(call))
+	outDir := call.Argument(1).String()
+	inputData := call.Argument(2).Export() //nolint:mnd
+
+	var opts []TemplateDirOpt
+	if o, ok := call.Argument(3).Export().(map[string]any); ok { //nolint:mnd
+		opts = append(opts, WithInclude(toStrings(o["include"])...), WithExclude(toStrings(o["exclude"])...))
+	}
+
+	ctx := call.Ctx
+	_, span := e.tracer.Start(ctx, "js:templateDir", trace.WithAttributes(
+		attribute.String("srcDir", srcDir),
+		attribute.String("outDir", outDir),
+	))
+	defer span.End()
+
+	if err := e.templateDir(call.Ctx, call.VM, srcDir, outDir, inputData, opts...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		panic(call.VM.NewGoError(err))
+	}
+
+	return goja.Undefined()
+}
+
+//nolint:cyclop
+func (e *Engine) templateDir(ctx context.Context, v *vm.VM, srcDir, outDir string, data any, opts ...TemplateDirOpt) error {
+	o := &templateDirOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	root := path.Clean(e.resolvePath(srcDir))
+
+	info, err := e.statFile(root)
+	if err != nil {
+		return fmt.Errorf("failed to read template dir %s: %w", srcDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrInvalidArg, srcDir)
+	}
+
+	// renderedDirs maps the relative path of each directory to its rendered output path
+	renderedDirs := map[string]string{".": outDir}
+
+	return e.walkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filePath == root {
+			return nil
+		}
+
+		rel := filePath
+		if root != "." {
+			rel = strings.TrimPrefix(filePath, root+"/")
+		}
+
+		if matchesAny(o.exclude, rel) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && len(o.include) > 0 && !matchesAny(o.include, rel) {
+			return nil
+		}
+
+		parentOut, ok := renderedDirs[path.Dir(rel)]
+		if !ok {
+			// The parent directory was skipped
+			return nil
+		}
+
+		name, err := e.templateName(ctx, v, filePath, d.Name(), data)
+		if err != nil {
+			return err
+		}
+
+		// A name rendering to nothing skips the file or directory
+		if name == "" {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		isTemplate := !d.IsDir() && strings.HasSuffix(name, templateExt)
+		name = strings.TrimSuffix(name, templateExt)
+
+		if !isValidName(name) {
+			return fmt.Errorf("%w: name of %s renders to %q, which isn't a single file or directory name", ErrInvalidArg, filePath, name)
+		}
+
+		outPath := path.Join(parentOut, name)
+
+		if !d.IsDir() && e.writesToDisk {
+			if err := os.MkdirAll(filepath.FromSlash(parentOut), os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", parentOut, err)
+			}
+		}
+
+		switch {
+		case d.IsDir():
+			renderedDirs[rel] = outPath
+		case isTemplate:
+			if err := e.templator.TemplateFile(ctx, v, filePath, outPath, data); err != nil {
+				if e.errorMode != CollectErrors {
+					return err
+				}
+				e.collectError(filePath, outPath, "", err)
+			}
+		default:
+			if err := e.copyFile(filePath, outPath); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// isValidName returns true if the rendered name is a single path element, so it can't write outside of its parent directory.
+func isValidName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// templateName renders any template actions in a file or directory name.
+func (e *Engine) templateName(ctx context.Context, v *vm.VM, filePath, name string, data any) (string, error) {
+	if !strings.Contains(name, e.templator.LeftDelim()) {
+		return name, nil
+	}
+
+	rendered, err := e.templator.TemplateStringInput(ctx, v, filePath, name, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render name of %s: %w", filePath, err)
+	}
+
+	return strings.TrimSpace(rendered), nil
+}
+
+func (e *Engine) copyFile(filePath, outPath string) error {
+	var data []byte
+	var err error
+
+	if e.readFS != nil {
+		data, err = fs.ReadFile(e.readFS, filePath)
+	} else {
+		data, err = os.ReadFile(filePath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	if err := e.templator.Write(outPath, data); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", outPath, err)
+	}
+
+	return nil
+}
+
+func matchesAny(globs []string, name string) bool {
+	for _, glob := range globs {
+		if utils.MatchGlob(glob, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toStrings(v any) []string {
+	values, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,145 @@
+package easytemplate_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateDir(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithSearchLocations([]string{"templates"}),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"templates/skeleton/README.md":                                  {Data: []byte("{{ not templated }}")},
+			"templates/skeleton/{{ .Local.pkg }}/client.go.stmpl":           {Data: []byte("package {{ .Local.pkg }}")},
+			"templates/skeleton/{{ .Local.pkg }}/ignored.tmp":               {Data: []byte("ignored")},
+			"templates/skeleton/{{ if .Local.docs }}docs{{ end }}/index.md": {Data: []byte("docs")},
+			"templates/skeleton/excluded/file.txt":                          {Data: []byte("excluded")},
+			"main.js":                                                       {Data: []byte(`templateDir("skeleton", "js", { pkg: "sdk" }, { exclude: ["excluded", "**/*.tmp"] });`)},
+		}),
+		easytemplate.WithWriteFunc(func(outFile string, data []byte) error {
+			written[outFile] = string(data)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateDir(context.Background(), "skeleton", "out", map[string]any{"pkg": "sdk", "docs": true}, easytemplate.WithExclude("**/*.tmp"))
+	require.NoError(t, err)
+
+	err = e.RunScript(context.Background(), "main.js")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"out/README.md":         "{{ not templated }}",
+		"out/sdk/client.go":     "package sdk",
+		"out/docs/index.md":     "docs",
+		"out/excluded/file.txt": "excluded",
+		"js/README.md":          "{{ not templated }}",
+		"js/sdk/client.go":      "package sdk",
+	}, written)
+}
+
+func TestEngine_TemplateDir_CreatesDirectoriesOnDisk(t *testing.T) {
+	outDir := t.TempDir()
+
+	e := easytemplate.New(
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"skeleton/{{ .Local.pkg }}/client.go.stmpl": {Data: []byte("package {{ .Local.pkg }}")},
+			"skeleton/docs/index.md":                    {Data: []byte("docs")},
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateDir(context.Background(), "skeleton", filepath.Join(outDir, "out"), map[string]any{"pkg": "sdk"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(outDir, "out", "sdk", "client.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package sdk", string(data))
+
+	data, err = os.ReadFile(filepath.Join(outDir, "out", "docs", "index.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "docs", string(data))
+}
+
+func TestEngine_TemplateDir_InvalidNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		pkg     string
+		wantErr string
+	}{
+		{
+			name:    "parent directory",
+			entry:   "skeleton/{{ .Local.pkg }}/client.go.stmpl",
+			pkg:     "..",
+			wantErr: `name of skeleton/{{ .Local.pkg }} renders to ".."`,
+		},
+		{
+			name:    "current directory",
+			entry:   "skeleton/{{ .Local.pkg }}/client.go.stmpl",
+			pkg:     ".",
+			wantErr: `name of skeleton/{{ .Local.pkg }} renders to "."`,
+		},
+		{
+			name:    "nested path",
+			entry:   "skeleton/{{ .Local.pkg }}/client.go.stmpl",
+			pkg:     "a/b",
+			wantErr: `name of skeleton/{{ .Local.pkg }} renders to "a/b"`,
+		},
+		{
+			name:    "escaping path",
+			entry:   "skeleton/{{ .Local.pkg }}.go.stmpl",
+			pkg:     "../../etc/passwd",
+			wantErr: `name of skeleton/{{ .Local.pkg }}.go.stmpl renders to "../../etc/passwd.go"`,
+		},
+		{
+			name:    "windows separator",
+			entry:   "skeleton/{{ .Local.pkg }}.go",
+			pkg:     `..\evil`,
+			wantErr: `name of skeleton/{{ .Local.pkg }}.go renders to "..\\evil.go"`,
+		},
+		{
+			name:    "only the template extension",
+			entry:   "skeleton/{{ .Local.pkg }}.stmpl",
+			pkg:     "",
+			wantErr: `name of skeleton/{{ .Local.pkg }}.stmpl renders to ""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written := map[string]string{}
+
+			e := easytemplate.New(
+				easytemplate.WithReadFileSystem(fstest.MapFS{
+					tt.entry: {Data: []byte("package main")},
+				}),
+				easytemplate.WithWriteFunc(func(outFile string, data []byte) error {
+					written[outFile] = string(data)
+					return nil
+				}),
+			)
+
+			err := e.Init(context.Background(), nil)
+			require.NoError(t, err)
+
+			err = e.TemplateDir(context.Background(), "skeleton", "out", map[string]any{"pkg": tt.pkg})
+			require.ErrorIs(t, err, easytemplate.ErrInvalidArg)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, written)
+		})
+	}
+}
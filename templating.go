@@ -8,9 +8,21 @@ import (
 )
 
 func (e *Engine) templateFileJS(call CallContext) goja.Value {
-	templateFile := call.Argument(0).String()
-	outFile := call.Argument(1).String()
-	inputData := call.Argument(2).Export() //nolint:mnd
+	templateFile := e.resolveRef(call.Argument(0).String(), callerFile(call))
+
+	var outFile string
+	var inputData any
+
+	switch outFileArg := call.Argument(1); {
+	case len(call.Arguments) == 2 && !isString(outFileArg): //nolint:mnd
+		// templateFile(templateFile, data) with the outFile provided by the template's front-matter
+		inputData = outFileArg.Export()
+	case goja.IsUndefined(outFileArg) || goja.IsNull(outFileArg):
+		inputData = call.Argument(2).Export() //nolint:mnd
+	default:
+		outFile = outFileArg.String()
+		inputData = call.Argument(2).Export() //nolint:mnd
+	}
 
 	ctx := call.Ctx
 	_, span := e.tracer.Start(ctx, "js:templateFile", trace.WithAttributes(
@@ -24,6 +36,11 @@ func (e *Engine) templateFileJS(call CallContext) goja.Value {
 		span.SetStatus(codes.Error, err.Error())
 		span.End()
 
+		if e.errorMode == CollectErrors {
+			e.collectError(templateFile, outFile, callerLocation(call), err)
+			return goja.Undefined()
+		}
+
 		panic(call.VM.NewGoError(err))
 	}
 
@@ -31,7 +48,7 @@ func (e *Engine) templateFileJS(call CallContext) goja.Value {
 }
 
 func (e *Engine) templateStringJS(call CallContext) goja.Value {
-	templateFile := call.Argument(0).String()
+	templateFile := e.resolveRef(call.Argument(0).String(), callerFile(call))
 	inputData := call.Argument(1).Export()
 
 	ctx := call.Ctx
@@ -83,3 +100,19 @@ func (e *Engine) recurseJS(call CallContext) goja.Value {
 
 	return call.VM.ToValue(output)
 }
+
+// callerFile returns the name of the script (or template for sjs blocks) making the call.
+func callerFile(call CallContext) string {
+	for _, frame := range call.VM.CaptureCallStack(0, nil) {
+		if name := frame.SrcName(); name != "" && name != "<native>" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+func isString(v goja.Value) bool {
+	_, ok := v.Export().(string)
+	return ok
+}
@@ -0,0 +1,50 @@
+package easytemplate
+
+import (
+	"reflect"
+
+	"github.com/speakeasy-api/easytemplate/internal/template"
+	"github.com/speakeasy-api/easytemplate/internal/tooling"
+)
+
+func init() {
+	tooling.Of = func(e any) tooling.Engine {
+		return engineTooling{e: e.(*Engine)} //nolint:forcetypeassert // the tooling only ever passes an *Engine
+	}
+}
+
+// engineTooling implements tooling.Engine, exposing the engine's internals to the easytemplate command without making them part
+// of the public API.
+type engineTooling struct {
+	e *Engine
+}
+
+func (t engineTooling) TemplateFuncNames() []string {
+	return t.e.templateFuncNames()
+}
+
+func (t engineTooling) JSGlobalNames() []string {
+	return t.e.jsGlobalNames()
+}
+
+func (t engineTooling) IsJSVariable(name string) bool {
+	if name == template.ContextGlobal {
+		return true
+	}
+
+	if _, ok := t.e.jsFuncs[name]; ok {
+		return false
+	}
+
+	global, ok := t.e.jsGlobals[name]
+
+	return ok && reflect.ValueOf(global).Kind() != reflect.Func
+}
+
+func (t engineTooling) LintSource(file, src string) []template.LintIssue {
+	return t.e.lintSource(file, src)
+}
+
+func (t engineTooling) Locate(ref, from string) (string, error) {
+	return t.e.locate(ref, from)
+}
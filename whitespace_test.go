@@ -0,0 +1,41 @@
+package easytemplate_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/speakeasy-api/easytemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_TemplateFile_WhitespaceHelpers(t *testing.T) {
+	written := map[string]string{}
+
+	e := easytemplate.New(
+		easytemplate.WithWhitespaceFuncs(),
+		easytemplate.WithNormalizer(easytemplate.Normalizer{
+			CollapseBlankLines:     true,
+			TrimTrailingWhitespace: true,
+			FinalNewline:           true,
+		}),
+		easytemplate.WithReadFileSystem(fstest.MapFS{
+			"model.stmpl": {Data: []byte("{{ comment \"//\" (wrap 20 .Local.doc) }}\ntype Model struct {\n{{- range .Local.fields }}\n{{ indent 1 . }}   \n\n{{ end }}\n}\n// {{ join \", \" .Local.fields }}\n\n\n")},
+		}),
+		easytemplate.WithWriteFunc(func(s string, b []byte) error {
+			written[s] = string(b)
+			return nil
+		}),
+	)
+
+	err := e.Init(context.Background(), nil)
+	require.NoError(t, err)
+
+	err = e.TemplateFile(context.Background(), "model.stmpl", "model.go", map[string]any{
+		"doc":    "Model is a model generated from the schema",
+		"fields": []string{"A int", "B string"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "// Model is a model\n// generated from the\n// schema\ntype Model struct {\n A int\n\n B string\n\n}\n// A int, B string\n", written["model.go"])
+}